@@ -0,0 +1,157 @@
+package mcpscan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/snyk/go-application-framework/pkg/workflow"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/errors"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/manifest"
+)
+
+// helpCommand is the positional argument that shows the help instead of scanning.
+const helpCommand = "help"
+
+// managedScannerOptions are scanner options the wrapper sets itself; they cannot be given on the command line.
+var managedScannerOptions = map[string]bool{
+	"analysis-url":       true,
+	"control-server":     true,
+	"control-server-H":   true,
+	"control-identifier": true,
+}
+
+// scanArgs is the command line of a scan, split into the flags handled by the wrapper through the
+// configuration and the arguments forwarded to the scanner.
+type scanArgs struct {
+	// Help is set by `snyk mcp-scan help` or --help.
+	Help bool
+	// ScannerFlags are the flags forwarded to the scanner, each followed by its value if given separately.
+	ScannerFlags []string
+	// Positionals are the configuration files and directories to scan.
+	Positionals []string
+}
+
+// parseScanArgs parses the raw command line of a scan. Flags of the wrapper and global CLI flags
+// are consumed, including their values given as --flag=value or --flag value; wrapper flags the
+// scanner also knows, such as --skills, are forwarded as well. All other flags must be options of
+// the scanner version that runs and are forwarded with their values. Everything after "--" is a
+// positional argument, and "help" only requests the help as the first positional argument.
+func parseScanArgs(rawArgs []string, wrapperFlags *pflag.FlagSet, scannerManifest *manifest.Manifest, version string) (scanArgs, error) {
+	flags := pflag.NewFlagSet(wrapperFlags.Name(), pflag.ContinueOnError)
+	flags.AddFlagSet(wrapperFlags)
+	flags.AddFlagSet(workflow.FlagsetFromConfigurationOptions(workflow.GetGlobalConfiguration()))
+
+	var parsed scanArgs
+	seenCommand := false
+	for i := 0; i < len(rawArgs); i++ {
+		arg := rawArgs[i]
+		switch {
+		case arg == "--":
+			parsed.Positionals = append(parsed.Positionals, rawArgs[i+1:]...)
+			return parsed, nil
+		case arg == "--help" || arg == "-h":
+			parsed.Help = true
+		case strings.HasPrefix(arg, "--"):
+			consumed, err := parsed.addLongFlag(arg, rawArgs[i+1:], flags, scannerManifest, version)
+			if err != nil {
+				return scanArgs{}, err
+			}
+			i += consumed
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			if len(arg) != 2 || flags.ShorthandLookup(arg[1:]) == nil {
+				return scanArgs{}, unknownFlagError(arg, version)
+			}
+		case !seenCommand && len(parsed.Positionals) == 0 && arg == flagSetName:
+			seenCommand = true
+		case len(parsed.Positionals) == 0 && arg == helpCommand:
+			parsed.Help = true
+		default:
+			parsed.Positionals = append(parsed.Positionals, arg)
+		}
+	}
+	return parsed, nil
+}
+
+// addLongFlag handles a --name or --name=value argument and returns how many of the following
+// arguments it consumed as its value.
+func (a *scanArgs) addLongFlag(arg string, rest []string, flags *pflag.FlagSet, scannerManifest *manifest.Manifest, version string) (int, error) {
+	name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+	option, isScannerOption := scannerManifest.LookupOption(version, name)
+
+	if flag := flags.Lookup(name); flag != nil {
+		takesValue := flag.Value.Type() != "bool" && flag.NoOptDefVal == ""
+		consumed := 0
+		if takesValue && !hasValue {
+			if len(rest) == 0 {
+				return 0, errors.NewInvalidFlagValueError(fmt.Sprintf("Flag --%s requires a value", name)).SnykError
+			}
+			consumed = 1
+		}
+		switch {
+		case !isScannerOption:
+		case option.Value == "":
+			// Switches of the scanner take no value, so --json=false must not reach it.
+			if enabled, err := strconv.ParseBool(value); !hasValue || (err == nil && enabled) {
+				a.ScannerFlags = append(a.ScannerFlags, "--"+name)
+			}
+		default:
+			a.ScannerFlags = append(a.ScannerFlags, arg)
+			a.ScannerFlags = append(a.ScannerFlags, rest[:consumed]...)
+		}
+		return consumed, nil
+	}
+
+	if !isScannerOption {
+		return 0, unknownFlagError(arg, version)
+	}
+	if managedScannerOptions[name] {
+		return 0, errors.NewInvalidFlagValueError(fmt.Sprintf("Flag --%s is set by snyk mcp-scan and cannot be given", name)).SnykError
+	}
+	a.ScannerFlags = append(a.ScannerFlags, arg)
+	switch {
+	case option.Value == "" && hasValue:
+		return 0, errors.NewInvalidFlagValueError(fmt.Sprintf("Flag --%s does not take a value", name)).SnykError
+	case option.Value == "" || option.OptionalValue || hasValue:
+		return 0, nil
+	case len(rest) == 0:
+		return 0, errors.NewInvalidFlagValueError(fmt.Sprintf("Flag --%s requires a value (%s)", name, option.Value)).SnykError
+	default:
+		a.ScannerFlags = append(a.ScannerFlags, rest[0])
+		return 1, nil
+	}
+}
+
+// hasScannerFlag reports whether the flag, given without leading dashes, is forwarded to the scanner.
+func (a scanArgs) hasScannerFlag(name string) bool {
+	for _, arg := range a.ScannerFlags {
+		if flagName, _, _ := strings.Cut(arg, "="); flagName == "--"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// scannerArgs returns the arguments of the scanner's scan command with the extra flags added
+// after the forwarded ones. Positional arguments come last, behind "--", so they are never read
+// as flags.
+func (a scanArgs) scannerArgs(extraFlags ...string) []string {
+	args := make([]string, 0, 2+len(a.ScannerFlags)+len(extraFlags)+len(a.Positionals))
+	args = append(args, "scan")
+	args = append(args, a.ScannerFlags...)
+	args = append(args, extraFlags...)
+	if len(a.Positionals) > 0 {
+		args = append(args, "--")
+		args = append(args, a.Positionals...)
+	}
+	return args
+}
+
+func unknownFlagError(arg, version string) error {
+	name, _, _ := strings.Cut(arg, "=")
+	return errors.NewInvalidFlagValueError(fmt.Sprintf(
+		"Unknown flag %s: it is neither a flag of snyk mcp-scan nor an option of mcp-scan %s", name, version)).SnykError
+}
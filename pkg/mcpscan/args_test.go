@@ -0,0 +1,119 @@
+package mcpscan //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"testing"
+
+	"github.com/snyk/error-catalog-golang-public/snyk_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/manifest"
+)
+
+const testUUID = "123e4567-e89b-12d3-a456-426614174000"
+
+func parseTestArgs(t *testing.T, rawArgs ...string) (scanArgs, error) {
+	t.Helper()
+	scannerManifest, err := manifest.Load()
+	require.NoError(t, err)
+	return parseScanArgs(rawArgs, getFlagSet(), scannerManifest, MCPScanBinaryVersion)
+}
+
+func TestParseScanArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		rawArgs   []string
+		flags     []string
+		positions []string
+		help      bool
+	}{
+		{
+			name:      "consumes wrapper flags in both forms",
+			rawArgs:   []string{"mcp-scan", "--experimental", "--tenant-id", testUUID, "--client-id=" + testUUID, "config.json"},
+			positions: []string{"config.json"},
+		},
+		{
+			name:      "consumes repeated wrapper flags",
+			rawArgs:   []string{"mcp-scan", "--pass-env", "AWS_PROFILE", "--pass-env=NVM_*", "--timeout", "90s", "config.json"},
+			positions: []string{"config.json"},
+		},
+		{
+			name:      "consumes global flags",
+			rawArgs:   []string{"-d", "mcp-scan", "--org", "my-org", "--insecure", "config.json"},
+			positions: []string{"config.json"},
+		},
+		{
+			name:      "forwards scanner options with their values",
+			rawArgs:   []string{"mcp-scan", "--server-timeout", "30", "--storage-file=state.json", "--verbose", "config.json"},
+			flags:     []string{"--server-timeout", "30", "--storage-file=state.json", "--verbose"},
+			positions: []string{"config.json"},
+		},
+		{
+			name:      "forwards wrapper flags the scanner supports",
+			rawArgs:   []string{"mcp-scan", "--json", "--skills", "config.json", "--skills=./skills"},
+			flags:     []string{"--json", "--skills", "--skills=./skills"},
+			positions: []string{"config.json"},
+		},
+		{
+			name:    "does not forward disabled switches",
+			rawArgs: []string{"mcp-scan", "--json=false"},
+		},
+		{
+			name:      "treats everything after -- as positional",
+			rawArgs:   []string{"mcp-scan", "--", "--tenant-id", "help"},
+			positions: []string{"--tenant-id", "help"},
+		},
+		{
+			name:    "help command",
+			rawArgs: []string{"mcp-scan", "--experimental", "help"},
+			help:    true,
+		},
+		{
+			name:    "help flag",
+			rawArgs: []string{"mcp-scan", "--help"},
+			help:    true,
+		},
+		{
+			name:      "path named help",
+			rawArgs:   []string{"mcp-scan", "config.json", "help"},
+			positions: []string{"config.json", "help"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := parseTestArgs(t, tt.rawArgs...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.flags, args.ScannerFlags)
+			assert.Equal(t, tt.positions, args.Positionals)
+			assert.Equal(t, tt.help, args.Help)
+		})
+	}
+}
+
+func TestParseScanArgs_Invalid(t *testing.T) {
+	for name, rawArgs := range map[string][]string{
+		"unknown flag":             {"mcp-scan", "--no-such-flag"},
+		"unknown short flag":       {"mcp-scan", "-x"},
+		"missing wrapper value":    {"mcp-scan", "--tenant-id"},
+		"missing scanner value":    {"mcp-scan", "--storage-file"},
+		"value for scanner switch": {"mcp-scan", "--verbose=yes"},
+		"managed scanner option":   {"mcp-scan", "--control-server", "https://example.com"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseTestArgs(t, rawArgs...)
+			var snykErr snyk_errors.Error
+			require.ErrorAs(t, err, &snykErr)
+		})
+	}
+}
+
+func TestScanArgs_ScannerArgs(t *testing.T) {
+	args, err := parseTestArgs(t, "mcp-scan", "--verbose", "--", "-config.json")
+	require.NoError(t, err)
+
+	assert.False(t, args.hasScannerFlag(FlagJSON))
+	assert.Equal(t,
+		[]string{"scan", "--verbose", "--json", "--", "-config.json"},
+		args.scannerArgs("--json"))
+}
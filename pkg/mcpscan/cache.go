@@ -0,0 +1,117 @@
+package mcpscan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/cache"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/errors"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/manifest"
+)
+
+const (
+	CacheWorkflowIDStr = ScanWorkflowIDStr + " cache"
+
+	cacheActionList   = "list"
+	cacheActionVerify = "verify"
+	cacheActionPrune  = "prune"
+	cacheActionClear  = "clear"
+)
+
+var (
+	CacheWorkflowID workflow.Identifier = workflow.NewWorkflowIdentifier(CacheWorkflowIDStr)
+
+	CacheDataTypeID workflow.Identifier = workflow.NewTypeIdentifier(CacheWorkflowID, "mcp-scan-cache")
+)
+
+// cacheReport is the JSON output of the cache command.
+type cacheReport struct {
+	Action    string        `json:"action"`
+	Directory string        `json:"directory"`
+	Entries   []cache.Entry `json:"entries"`
+}
+
+// cacheAction returns the action following "cache" in the raw command line arguments.
+func cacheAction(rawArgs []string) string {
+	seenCache := false
+	for _, a := range rawArgs {
+		if strings.HasPrefix(a, "-") {
+			continue
+		}
+		if seenCache {
+			return a
+		}
+		seenCache = a == "cache"
+	}
+	return ""
+}
+
+// CacheWorkflow lists, verifies and removes the scanner binaries in the CLI cache directory.
+func CacheWorkflow(ctx workflow.InvocationContext, _ []workflow.Data) ([]workflow.Data, error) {
+	config := ctx.GetConfiguration()
+	logger := ctx.GetEnhancedLogger()
+
+	if !config.GetBool(FlagExperimental) {
+		logger.Debug().Msg("Required experimental flag is not present")
+		return nil, errors.NewCommandIsExperimentalError().SnykError
+	}
+
+	scannerManifest, err := manifest.Load()
+	if err != nil {
+		return nil, err
+	}
+	dir := cache.Dir(config)
+	action := cacheAction(config.GetStringSlice(configuration.RAW_CMD_ARGS))
+
+	var entries []cache.Entry
+	var title string
+	switch action {
+	case cacheActionList:
+		title = fmt.Sprintf("Cached mcp-scan binaries in %s:", dir)
+		entries, err = cache.List(dir, scannerManifest)
+	case cacheActionVerify:
+		title = fmt.Sprintf("Verified mcp-scan binaries in %s:", dir)
+		entries, err = cache.List(dir, scannerManifest)
+		if err == nil {
+			err = cache.Verify(entries, scannerManifest)
+		}
+	case cacheActionPrune:
+		title = fmt.Sprintf("Removed from %s:", dir)
+		// The binary a scan would use is kept, so prune refuses to run when it cannot be resolved
+		// rather than removing a binary that is still in use.
+		binary, resolveErr := resolveScannerBinary(config, scannerManifest, manifest.CurrentPlatform())
+		if resolveErr != nil {
+			return nil, resolveErr
+		}
+		entries, err = cache.Prune(dir, scannerManifest, binary.Asset)
+	case cacheActionClear:
+		title = fmt.Sprintf("Removed from %s:", dir)
+		entries, err = cache.Clear(dir, scannerManifest)
+	default:
+		return nil, errors.NewInvalidFlagValueError(fmt.Sprintf("Unknown cache action %q, expected one of %s, %s, %s or %s.",
+			action, cacheActionList, cacheActionVerify, cacheActionPrune, cacheActionClear)).SnykError
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("action", action).Msg("Failed to manage mcp-scan cache")
+		return nil, err
+	}
+
+	text := cache.Render(title, entries)
+	if action == cacheActionVerify {
+		for _, entry := range entries {
+			if entry.Status != cache.StatusMismatch {
+				continue
+			}
+			if outErr := ctx.GetUserInterface().Output(text); outErr != nil {
+				logger.Error().Err(outErr).Msg("Failed to output cache verification report")
+			}
+			return nil, errors.NewCorruptCacheError(fmt.Sprintf(
+				"The cached binary %s does not match its published checksum. Run `snyk mcp-scan cache prune` to remove it.", entry.Name)).SnykError
+		}
+	}
+
+	return newReportOutput(config, CacheDataTypeID, cacheReport{Action: action, Directory: dir, Entries: entries}, text)
+}
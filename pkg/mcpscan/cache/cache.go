@@ -0,0 +1,200 @@
+// Package cache manages the scanner binaries downloaded into the CLI cache directory.
+package cache
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/snyk/go-application-framework/pkg/configuration"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/manifest"
+)
+
+const (
+	// BinaryPrefix starts the name of every file the scanner keeps in the cache directory.
+	BinaryPrefix = "mcp-scan-"
+	// SignatureSuffix names the detached minisign signature kept next to a cached binary.
+	SignatureSuffix = ".minisig"
+	// downloadMarker is part of the temp file name used while a download is in progress.
+	downloadMarker = ".download-"
+)
+
+// Verification states of a cached binary.
+const (
+	StatusOK       = "ok"
+	StatusMismatch = "checksum mismatch"
+	StatusUnknown  = "not in manifest"
+	StatusOrphaned = "incomplete download"
+)
+
+// Entry is a scanner file in the cache directory.
+type Entry struct {
+	Name     string    `json:"name"`
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"modified"`
+	Version  string    `json:"version,omitempty"`
+	Platform string    `json:"platform,omitempty"`
+	// Orphaned marks a partial download left behind by an interrupted run.
+	Orphaned bool   `json:"orphaned"`
+	Status   string `json:"status,omitempty"`
+}
+
+// Dir returns the directory scanner binaries are cached in.
+func Dir(config configuration.Configuration) string {
+	if dir := config.GetString(configuration.CACHE_PATH); dir != "" {
+		return dir
+	}
+	return os.TempDir()
+}
+
+// List returns the scanner files in dir, annotated with the version and platform the manifest
+// lists them under. A missing directory has no entries.
+func List(dir string, m *manifest.Manifest) ([]Entry, error) {
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory %s: %w", dir, err)
+	}
+
+	entries := []Entry{}
+	for _, f := range files {
+		if !f.Type().IsRegular() || !strings.HasPrefix(f.Name(), BinaryPrefix) ||
+			strings.HasSuffix(f.Name(), LockSuffix) || strings.HasSuffix(f.Name(), SignatureSuffix) {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		entry := Entry{
+			Name:     f.Name(),
+			Path:     filepath.Join(dir, f.Name()),
+			Size:     info.Size(),
+			ModTime:  info.ModTime(),
+			Orphaned: strings.Contains(f.Name(), downloadMarker),
+		}
+		if !entry.Orphaned {
+			entry.Version, entry.Platform, _ = lookupAsset(m, entry.Name)
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// lookupAsset finds the manifest entry of a release asset by name.
+func lookupAsset(m *manifest.Manifest, name string) (version, platform string, asset manifest.Asset) {
+	for _, v := range m.Versions() {
+		for p, a := range m.Releases[v] {
+			if a.Name == name {
+				return v, p, a
+			}
+		}
+	}
+	return "", "", manifest.Asset{}
+}
+
+// Verify sets the status of each entry by comparing cached binaries against the manifest checksums.
+func Verify(entries []Entry, m *manifest.Manifest) error {
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Orphaned {
+			entry.Status = StatusOrphaned
+			continue
+		}
+		_, _, asset := lookupAsset(m, entry.Name)
+		if asset.Name == "" {
+			entry.Status = StatusUnknown
+			continue
+		}
+		checksum, err := sha256File(entry.Path)
+		if err != nil {
+			return err
+		}
+		entry.Status = StatusOK
+		if !strings.EqualFold(checksum, asset.Checksum) {
+			entry.Status = StatusMismatch
+		}
+	}
+	return nil
+}
+
+// Prune removes everything but the verified binaries named in keep, i.e. partial downloads,
+// binaries of other versions and binaries that fail verification. It returns the removed entries.
+func Prune(dir string, m *manifest.Manifest, keep ...string) ([]Entry, error) {
+	entries, err := List(dir, m)
+	if err != nil {
+		return nil, err
+	}
+	if err := Verify(entries, m); err != nil {
+		return nil, err
+	}
+
+	kept := map[string]bool{}
+	for _, name := range keep {
+		kept[name] = true
+	}
+	stale := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Status == StatusOK && kept[entry.Name] {
+			continue
+		}
+		stale = append(stale, entry)
+	}
+	return remove(stale)
+}
+
+// Clear removes all scanner files from dir and returns the removed entries.
+func Clear(dir string, m *manifest.Manifest) ([]Entry, error) {
+	entries, err := List(dir, m)
+	if err != nil {
+		return nil, err
+	}
+	return remove(entries)
+}
+
+// remove deletes the entries' files along with their signatures. Entries locked by a running scan
+// or download are skipped.
+func remove(entries []Entry) ([]Entry, error) {
+	removed := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		unlock, ok := tryLock(entry.Path)
+		if !ok {
+			continue
+		}
+		err := os.Remove(entry.Path)
+		if sigErr := os.Remove(entry.Path + SignatureSuffix); err == nil && !errors.Is(sigErr, os.ErrNotExist) {
+			err = sigErr
+		}
+		unlock()
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("failed to remove %s: %w", entry.Path, err)
+		}
+		removed = append(removed, entry)
+	}
+	return removed, nil
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s for checksum verification: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %s for checksum verification: %w", path, err)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,157 @@
+package cache_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/cache"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/manifest"
+)
+
+const (
+	currentAsset = "mcp-scan-1.1.0-linux-x86_64"
+	oldAsset     = "mcp-scan-1.0.0-linux-x86_64"
+)
+
+func checksum(content string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+}
+
+func newTestManifest(t *testing.T) *manifest.Manifest {
+	t.Helper()
+	m, err := manifest.Parse([]byte(`{"versions": {
+		"1.0.0": {"linux/amd64": {"asset": "` + oldAsset + `", "sha256": "` + checksum("old") + `"}},
+		"1.1.0": {"linux/amd64": {"asset": "` + currentAsset + `", "sha256": "` + checksum("current") + `"}}
+	}, "options": {"1.0.0": [], "1.1.0": []}}`))
+	require.NoError(t, err)
+	return m
+}
+
+// newTestCache populates a cache directory with a current and an old binary and their signatures,
+// an interrupted download, a binary unknown to the manifest and an unrelated file of the CLI.
+func newTestCache(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		currentAsset:                         "current",
+		currentAsset + cache.SignatureSuffix: "signature",
+		oldAsset:                             "old",
+		oldAsset + cache.SignatureSuffix:     "signature",
+		currentAsset + ".download-123":       "curr",
+		"mcp-scan-0.1.0-linux-x86_64":        "ancient",
+		"snyk-cli-unrelated-cache-file.json": "{}",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return dir
+}
+
+func names(entries []cache.Entry) []string {
+	result := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry.Name)
+	}
+	return result
+}
+
+// remainingFiles lists the files left in dir, ignoring lock files.
+func remainingFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	result := []string{}
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), cache.LockSuffix) {
+			result = append(result, f.Name())
+		}
+	}
+	return result
+}
+
+func TestList(t *testing.T) {
+	m := newTestManifest(t)
+	entries, err := cache.List(newTestCache(t), m)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"mcp-scan-0.1.0-linux-x86_64", oldAsset, currentAsset, currentAsset + ".download-123"}, names(entries))
+	assert.Empty(t, entries[0].Version)
+	assert.Equal(t, "1.0.0", entries[1].Version)
+	assert.Equal(t, "linux/amd64", entries[1].Platform)
+	assert.True(t, entries[3].Orphaned)
+
+	entries, err = cache.List(filepath.Join(t.TempDir(), "missing"), m)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestVerify(t *testing.T) {
+	m := newTestManifest(t)
+	dir := newTestCache(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, oldAsset), []byte("tampered"), 0o600))
+
+	entries, err := cache.List(dir, m)
+	require.NoError(t, err)
+	require.NoError(t, cache.Verify(entries, m))
+
+	statuses := map[string]string{}
+	for _, entry := range entries {
+		statuses[entry.Name] = entry.Status
+	}
+	assert.Equal(t, map[string]string{
+		"mcp-scan-0.1.0-linux-x86_64":  cache.StatusUnknown,
+		oldAsset:                       cache.StatusMismatch,
+		currentAsset:                   cache.StatusOK,
+		currentAsset + ".download-123": cache.StatusOrphaned,
+	}, statuses)
+}
+
+func TestPrune(t *testing.T) {
+	m := newTestManifest(t)
+	dir := newTestCache(t)
+
+	removed, err := cache.Prune(dir, m, currentAsset)
+	require.NoError(t, err)
+	assert.Len(t, removed, 3)
+
+	assert.Equal(t, []string{currentAsset, currentAsset + cache.SignatureSuffix, "snyk-cli-unrelated-cache-file.json"}, remainingFiles(t, dir))
+}
+
+func TestPrune_RemovesCorruptKeptBinary(t *testing.T) {
+	m := newTestManifest(t)
+	dir := newTestCache(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, currentAsset), []byte("tampered"), 0o600))
+
+	removed, err := cache.Prune(dir, m, currentAsset)
+	require.NoError(t, err)
+	assert.Contains(t, names(removed), currentAsset)
+}
+
+func TestClear(t *testing.T) {
+	dir := newTestCache(t)
+
+	removed, err := cache.Clear(dir, newTestManifest(t))
+	require.NoError(t, err)
+	assert.Len(t, removed, 4)
+
+	assert.Equal(t, []string{"snyk-cli-unrelated-cache-file.json"}, remainingFiles(t, dir), "files not belonging to the scanner are kept")
+}
+
+func TestRender(t *testing.T) {
+	entries, err := cache.List(newTestCache(t), newTestManifest(t))
+	require.NoError(t, err)
+
+	out := cache.Render("Cached mcp-scan binaries:", entries)
+	assert.Contains(t, out, currentAsset+" (7 B")
+	assert.Contains(t, out, "version 1.1.0, linux/amd64")
+	assert.Contains(t, out, "incomplete download")
+	assert.Contains(t, out, "4 file(s)")
+
+	assert.Contains(t, cache.Render("Removed:", nil), "(none)")
+}
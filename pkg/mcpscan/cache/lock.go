@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// LockSuffix is appended to a binary's cache path to name the file coordinating access to it.
+const LockSuffix = ".lock"
+
+// lockRetryDelay is how often a busy lock is retried while waiting.
+const lockRetryDelay = 200 * time.Millisecond
+
+// ErrLockTimeout is returned when another process holds a cache lock for longer than the wait allows.
+var ErrLockTimeout = errors.New("timed out waiting for cache lock")
+
+// LockPath returns the lock file guarding the cached binary at path, including its in-progress downloads.
+func LockPath(path string) string {
+	if i := strings.Index(path, downloadMarker); i >= 0 {
+		path = path[:i]
+	}
+	return path + LockSuffix
+}
+
+// Lock takes the exclusive lock for the cached binary at path, waiting up to timeout for other
+// processes to release it. The returned function releases the lock.
+func Lock(ctx context.Context, path string, timeout time.Duration) (func(), error) {
+	return lock(ctx, path, timeout, (*flock.Flock).TryLockContext)
+}
+
+// LockShared takes a shared lock for the cached binary at path, which keeps other processes from
+// replacing or removing it while it is in use. The returned function releases the lock.
+func LockShared(ctx context.Context, path string, timeout time.Duration) (func(), error) {
+	return lock(ctx, path, timeout, (*flock.Flock).TryRLockContext)
+}
+
+func lock(ctx context.Context, path string, timeout time.Duration,
+	tryLock func(*flock.Flock, context.Context, time.Duration) (bool, error),
+) (func(), error) {
+	fileLock := flock.New(LockPath(path))
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	locked, err := tryLock(fileLock, waitCtx, lockRetryDelay)
+	if !locked {
+		if err == nil || errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w %s after %s", ErrLockTimeout, fileLock.Path(), timeout)
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", fileLock.Path(), err)
+	}
+	return func() { _ = fileLock.Unlock() }, nil
+}
+
+// tryLock takes the lock for the cached binary at path only if no other process holds it.
+func tryLock(path string) (func(), bool) {
+	lock := flock.New(LockPath(path))
+	locked, err := lock.TryLock()
+	if err != nil || !locked {
+		return nil, false
+	}
+	return func() { _ = lock.Unlock() }, true
+}
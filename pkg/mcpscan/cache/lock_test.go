@@ -0,0 +1,48 @@
+package cache_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/cache"
+)
+
+func TestLockPath(t *testing.T) {
+	assert.Equal(t, "/cache/"+currentAsset+".lock", cache.LockPath("/cache/"+currentAsset))
+	assert.Equal(t, "/cache/"+currentAsset+".lock", cache.LockPath("/cache/"+currentAsset+".download-42"))
+}
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), currentAsset)
+
+	unlock, err := cache.Lock(context.Background(), path, time.Second)
+	require.NoError(t, err)
+
+	_, err = cache.Lock(context.Background(), path, 300*time.Millisecond)
+	require.ErrorIs(t, err, cache.ErrLockTimeout)
+
+	unlock()
+	unlockAgain, err := cache.Lock(context.Background(), path, time.Second)
+	require.NoError(t, err)
+	unlockAgain()
+}
+
+func TestClear_SkipsLockedBinaries(t *testing.T) {
+	dir := newTestCache(t)
+	unlock, err := cache.Lock(context.Background(), filepath.Join(dir, currentAsset), time.Second)
+	require.NoError(t, err)
+	defer unlock()
+
+	removed, err := cache.Clear(dir, newTestManifest(t))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mcp-scan-0.1.0-linux-x86_64", oldAsset}, names(removed))
+
+	_, err = os.Stat(filepath.Join(dir, currentAsset))
+	assert.NoError(t, err, "binary in use is kept")
+}
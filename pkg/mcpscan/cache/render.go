@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Render renders a plain text table of cache entries headed by title.
+func Render(title string, entries []Entry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", title)
+	if len(entries) == 0 {
+		b.WriteString("  (none)\n")
+		return b.String()
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+		details := []string{formatSize(entry.Size), entry.ModTime.Format("2006-01-02 15:04")}
+		switch {
+		case entry.Orphaned:
+			details = append(details, "incomplete download")
+		case entry.Version != "":
+			details = append(details, "version "+entry.Version, entry.Platform)
+		}
+		if entry.Status != "" && entry.Status != StatusOrphaned {
+			details = append(details, entry.Status)
+		}
+		fmt.Fprintf(&b, "  %s (%s)\n", entry.Name, strings.Join(details, ", "))
+	}
+	fmt.Fprintf(&b, "%d file(s), %s\n", len(entries), formatSize(total))
+	return b.String()
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,91 @@
+package mcpscan //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCacheTestContext(t *testing.T, cacheDir string, args ...string) *mocks.MockInvocationContext {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	logger := zerolog.Nop()
+
+	config := configuration.NewWithOpts()
+	config.Set(configuration.CACHE_PATH, cacheDir)
+	config.Set(FlagExperimental, true)
+	config.Set(configuration.RAW_CMD_ARGS, append([]string{"mcp-scan", "cache"}, args...))
+
+	ictx := mocks.NewMockInvocationContext(ctrl)
+	ictx.EXPECT().GetEnhancedLogger().Return(&logger).AnyTimes()
+	ictx.EXPECT().GetConfiguration().Return(config).AnyTimes()
+	ictx.EXPECT().GetUserInterface().Return(mocks.NewMockUserInterface(ctrl)).AnyTimes()
+	return ictx
+}
+
+func TestCacheAction(t *testing.T) {
+	assert.Equal(t, "list", cacheAction([]string{"mcp-scan", "cache", "list"}))
+	assert.Equal(t, "prune", cacheAction([]string{"mcp-scan", "--experimental", "cache", "--json", "prune"}))
+	assert.Empty(t, cacheAction([]string{"mcp-scan", "cache"}))
+}
+
+func TestCacheWorkflow_Clear(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"mcp-scan-0.1.0-linux-x86_64", "mcp-scan-0.1.0-linux-x86_64.download-1", "other"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o600))
+	}
+
+	ictx := newCacheTestContext(t, dir, "clear")
+	ictx.GetConfiguration().Set(FlagJSON, true)
+	output, err := CacheWorkflow(ictx, nil)
+	require.NoError(t, err)
+	require.Len(t, output, 1)
+
+	var report cacheReport
+	require.NoError(t, json.Unmarshal(output[0].GetPayload().([]byte), &report))
+	assert.Equal(t, "clear", report.Action)
+	assert.Len(t, report.Entries, 2)
+
+	_, err = os.Stat(filepath.Join(dir, "mcp-scan-0.1.0-linux-x86_64"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, err = os.Stat(filepath.Join(dir, "other"))
+	assert.NoError(t, err)
+}
+
+func TestCacheWorkflow_PruneRefusesUnresolvableBinary(t *testing.T) {
+	dir := t.TempDir()
+	cached := filepath.Join(dir, "mcp-scan-"+MCPScanBinaryVersion+"-linux-x86_64")
+	require.NoError(t, os.WriteFile(cached, []byte("x"), 0o600))
+
+	ictx := newCacheTestContext(t, dir, "prune")
+	ictx.GetConfiguration().Set(FlagScannerVersion, "not-a-version")
+	_, err := CacheWorkflow(ictx, nil)
+	require.Error(t, err)
+
+	_, err = os.Stat(cached)
+	assert.NoError(t, err, "prune must not remove binaries when the one in use cannot be resolved")
+}
+
+func TestCacheWorkflow_UnknownAction(t *testing.T) {
+	_, err := CacheWorkflow(newCacheTestContext(t, t.TempDir(), "purge"), nil)
+	assert.Error(t, err)
+}
+
+func TestCacheWorkflow_ListText(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mcp-scan-0.1.0-linux-x86_64"), []byte("x"), 0o600))
+
+	output, err := CacheWorkflow(newCacheTestContext(t, dir, "list"), nil)
+	require.NoError(t, err)
+	require.Len(t, output, 1)
+	assert.Equal(t, contentTypeText, output[0].GetContentType())
+	assert.Contains(t, output[0].GetPayload(), "mcp-scan-0.1.0-linux-x86_64")
+}
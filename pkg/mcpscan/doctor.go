@@ -0,0 +1,398 @@
+package mcpscan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	localworkflows "github.com/snyk/go-application-framework/pkg/local_workflows"
+	"github.com/snyk/go-application-framework/pkg/networking/certs"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+	"github.com/spf13/pflag"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/cache"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/constants"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/errors"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/helpers"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/helpers/tenantsapi"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/manifest"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/runner"
+)
+
+const (
+	DoctorWorkflowIDStr = ScanWorkflowIDStr + " doctor"
+
+	// endpointCheckTimeout bounds each reachability check of a Snyk endpoint.
+	endpointCheckTimeout = 10 * time.Second
+)
+
+var (
+	DoctorWorkflowID workflow.Identifier = workflow.NewWorkflowIdentifier(DoctorWorkflowIDStr)
+
+	DoctorDataTypeID workflow.Identifier = workflow.NewTypeIdentifier(DoctorWorkflowID, "mcp-scan-doctor")
+)
+
+// checkStatus is the outcome of a single doctor check.
+type checkStatus string
+
+const (
+	checkPass checkStatus = "pass"
+	checkWarn checkStatus = "warn"
+	checkFail checkStatus = "fail"
+	checkSkip checkStatus = "skip"
+)
+
+// doctorCheck is a single step of the doctor report. Hint tells how to fix a failed or
+// suspicious check.
+type doctorCheck struct {
+	Name   string      `json:"name"`
+	Status checkStatus `json:"status"`
+	Detail string      `json:"detail,omitempty"`
+	Hint   string      `json:"hint,omitempty"`
+}
+
+// doctorReport is the JSON output of the doctor command.
+type doctorReport struct {
+	Passed bool          `json:"passed"`
+	Checks []doctorCheck `json:"checks"`
+}
+
+func getDoctorFlagSet() *pflag.FlagSet {
+	flagSet := pflag.NewFlagSet(flagSetName+" doctor", pflag.ExitOnError)
+	flagSet.Bool(FlagExperimental, false, "This is an experiment feature that will contain breaking changes in future revisions")
+	flagSet.Bool(FlagJSON, false, "Output in JSON format")
+	flagSet.String(FlagTenantID, "", "Tenant ID whose push key is checked, required when you have access to several tenants")
+	flagSet.String(FlagScannerBinary, "", "Path to a pre-provisioned mcp-scan binary to check instead of the cache")
+	flagSet.String(FlagScannerVersion, "", "Version of mcp-scan to check, defaults to "+MCPScanBinaryVersion)
+	flagSet.String(FlagScannerDownloadURL, "", "Download URL template for the mcp-scan binary, supports {version}, {tag} and {asset} placeholders")
+	flagSet.StringArray(FlagScannerDownloadHeader, nil, "Additional \"Name: value\" header sent when downloading the mcp-scan binary, can be repeated")
+	return flagSet
+}
+
+// DoctorWorkflow checks step by step everything a scan depends on and reports what is broken
+// and how to fix it.
+func DoctorWorkflow(ctx workflow.InvocationContext, _ []workflow.Data) ([]workflow.Data, error) {
+	config := ctx.GetConfiguration()
+	logger := ctx.GetEnhancedLogger()
+
+	if !config.GetBool(FlagExperimental) {
+		logger.Debug().Msg("Required experimental flag is not present")
+		return nil, errors.NewCommandIsExperimentalError().SnykError
+	}
+
+	report := runDoctor(ctx)
+	text := renderDoctorReport(report)
+
+	if !report.Passed {
+		output := text
+		if config.GetBool(FlagJSON) {
+			payload, err := json.Marshal(report)
+			if err != nil {
+				return nil, fmt.Errorf("failed to serialize doctor report: %w", err)
+			}
+			output = string(payload)
+		}
+		if outErr := ctx.GetUserInterface().Output(output); outErr != nil {
+			logger.Error().Err(outErr).Msg("Failed to output doctor report")
+		}
+		failed := 0
+		for _, check := range report.Checks {
+			if check.Status == checkFail {
+				failed++
+			}
+		}
+		return nil, errors.NewDiagnosticsFailedError(fmt.Sprintf("%d mcp-scan doctor check(s) failed, see the hints above.", failed)).SnykError
+	}
+
+	return newReportOutput(config, DoctorDataTypeID, report, text)
+}
+
+// runDoctor runs all checks in order; checks whose prerequisites failed are skipped.
+func runDoctor(ctx workflow.InvocationContext) *doctorReport {
+	config := ctx.GetConfiguration()
+	apiURL := config.GetString(configuration.API_URL)
+
+	report := &doctorReport{Passed: true}
+	add := func(check doctorCheck) {
+		if check.Status == checkFail {
+			report.Passed = false
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	authenticated := checkAuthentication(ctx)
+	add(authenticated)
+
+	tenantID := config.GetString(FlagTenantID)
+	if authenticated.Status != checkPass {
+		add(doctorCheck{Name: "Tenants", Status: checkSkip, Detail: "requires authentication"})
+		add(doctorCheck{Name: "Push key", Status: checkSkip, Detail: "requires authentication"})
+	} else {
+		var tenants doctorCheck
+		tenants, tenantID = checkTenants(ctx, tenantID)
+		add(tenants)
+		add(checkPushKey(ctx, tenantID))
+	}
+
+	add(checkEndpoint(ctx, "Analysis endpoint", apiURL+"/hidden/mcp-scan/analysis-machine?version=2025-09-02"))
+	add(checkEndpoint(ctx, "Control endpoint", apiURL+"/hidden/mcp-scan/push?version=2025-08-28"))
+
+	binaryCheck, binary := checkScannerBinary(ctx)
+	add(binaryCheck)
+	add(checkUpstreamProxy(apiURL))
+	add(checkCertificates(ctx, binary.Version))
+	return report
+}
+
+func checkAuthentication(ctx workflow.InvocationContext) doctorCheck {
+	check := doctorCheck{Name: "Authentication"}
+	if _, err := ctx.GetEngine().InvokeWithConfig(localworkflows.WORKFLOWID_WHOAMI, ctx.GetConfiguration()); err != nil {
+		check.Status = checkFail
+		check.Detail = err.Error()
+		check.Hint = "Run `snyk auth` or set SNYK_TOKEN. Without authentication, scans need --client-id and cannot use --no-upload."
+		return check
+	}
+	check.Status = checkPass
+	check.Detail = "authenticated"
+	return check
+}
+
+// checkTenants lists the tenants of the user and returns the tenant whose push key is checked.
+func checkTenants(ctx workflow.InvocationContext, tenantID string) (doctorCheck, string) {
+	check := doctorCheck{Name: "Tenants"}
+	client, err := tenantsapi.NewClientWithResponses(ctx.GetConfiguration().GetString(configuration.API_URL), ctx.GetNetworkAccess().GetHttpClient())
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		return check, ""
+	}
+	limit := int32(100)
+	resp, err := tenantsapi.ListTenants(ctx.Context(), client, &tenantsapi.ListTenantsParams{Limit: &limit})
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		check.Hint = "Make sure your account has access to a tenant with Snyk Evo enabled."
+		return check, ""
+	}
+
+	switch {
+	case len(resp.Tenants) == 0:
+		check.Status, check.Detail = checkFail, "no tenants are available to your account"
+		check.Hint = "Ask a tenant administrator to invite you to a tenant with Snyk Evo enabled."
+		return check, ""
+	case tenantID != "":
+		for _, tenant := range resp.Tenants {
+			if tenant.ID == tenantID {
+				check.Status, check.Detail = checkPass, fmt.Sprintf("tenant %s (%s) is available", tenant.Name, tenant.ID)
+				return check, tenantID
+			}
+		}
+		check.Status, check.Detail = checkFail, fmt.Sprintf("tenant %s is not among the %d tenant(s) available to your account", tenantID, len(resp.Tenants))
+		if len(resp.Tenants) >= int(limit) {
+			// Only the first page of tenants was listed, the tenant may still be available
+			check.Status = checkWarn
+		}
+		check.Hint = "Check --tenant-id or SNYK_TENANT_ID."
+		return check, tenantID
+	case len(resp.Tenants) == 1:
+		check.Status, check.Detail = checkPass, fmt.Sprintf("tenant %s (%s) is available", resp.Tenants[0].Name, resp.Tenants[0].ID)
+		return check, resp.Tenants[0].ID
+	default:
+		check.Status, check.Detail = checkWarn, fmt.Sprintf("%d tenants are available", len(resp.Tenants))
+		check.Hint = "Pass --tenant-id to check the push key of a tenant; --json and --sarif scans require it as well."
+		return check, ""
+	}
+}
+
+func checkPushKey(ctx workflow.InvocationContext, tenantID string) doctorCheck {
+	check := doctorCheck{Name: "Push key"}
+	if tenantID == "" {
+		check.Status, check.Detail = checkSkip, "requires a tenant"
+		return check
+	}
+	clientID, err := helpers.GetClientID(ctx, tenantID)
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		switch errorString := strings.ToLower(err.Error()); {
+		case strings.Contains(errorString, "forbidden"):
+			check.Hint = "Retrieving the push key requires the evo or tenant-admin role on the tenant."
+		case strings.Contains(errorString, "unauthorized"):
+			check.Hint = "Run `snyk auth` to re-authenticate."
+		default:
+			check.Hint = "Retry later or pass the client ID with --client-id."
+		}
+		return check
+	}
+	check.Status, check.Detail = checkPass, "client ID "+redactClientID(clientID)
+	return check
+}
+
+// checkEndpoint reports whether a Snyk endpoint answers at all; the status code of a plain GET
+// request does not matter, as the endpoints only accept the scanner's requests.
+func checkEndpoint(ctx workflow.InvocationContext, name, endpointURL string) doctorCheck {
+	check := doctorCheck{Name: name}
+	requestCtx, cancel := context.WithTimeout(ctx.Context(), endpointCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(requestCtx, http.MethodGet, endpointURL, http.NoBody)
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		return check
+	}
+	resp, err := ctx.GetNetworkAccess().GetHttpClient().Do(req)
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		check.Hint = "Check your network connection, firewall rules and the HTTPS_PROXY setting, and that --api or SNYK_API point to the right Snyk region."
+		return check
+	}
+	resp.Body.Close()
+
+	check.Detail = fmt.Sprintf("%s answered with %s", redactURL(endpointURL), resp.Status)
+	check.Status = checkPass
+	if resp.StatusCode >= http.StatusInternalServerError {
+		check.Status = checkWarn
+		check.Hint = "The endpoint is reachable but currently failing; retry later."
+	}
+	return check
+}
+
+// checkScannerBinary checks the pre-provisioned binary, or the cached binary and, if it is not
+// cached yet, that it can be downloaded.
+func checkScannerBinary(ctx workflow.InvocationContext) (doctorCheck, runner.Binary) {
+	config := ctx.GetConfiguration()
+	check := doctorCheck{Name: "Scanner binary"}
+	scannerManifest, err := manifest.Load()
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		return check, runner.Binary{Version: MCPScanBinaryVersion}
+	}
+	binary, err := resolveScannerBinary(config, scannerManifest, manifest.CurrentPlatform())
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		check.Hint = fmt.Sprintf("Check --%s; mcp-scan is not available for every platform.", FlagScannerVersion)
+		binary.Version = MCPScanBinaryVersion
+		return check, binary
+	}
+
+	if binary.LocalPath != "" {
+		if err := runner.VerifyLocalBinary(ctx, binary); err != nil {
+			check.Status, check.Detail = checkFail, err.Error()
+			check.Hint = fmt.Sprintf("Provision the mcp-scan %s binary for this platform at --%s.", binary.Version, FlagScannerBinary)
+			return check, binary
+		}
+		check.Status, check.Detail = checkPass, fmt.Sprintf("mcp-scan %s at %s is verified", binary.Version, binary.LocalPath)
+		return check, binary
+	}
+
+	cachePath := filepath.Join(cache.Dir(config), binary.Asset)
+	if _, statErr := os.Stat(cachePath); statErr == nil {
+		entries := []cache.Entry{{Name: binary.Asset, Path: cachePath}}
+		if err := cache.Verify(entries, scannerManifest); err != nil {
+			check.Status, check.Detail = checkFail, err.Error()
+			return check, binary
+		}
+		if entries[0].Status != cache.StatusOK {
+			check.Status, check.Detail = checkFail, fmt.Sprintf("cached binary %s: %s", cachePath, entries[0].Status)
+			check.Hint = "Run `snyk mcp-scan cache prune --experimental` to remove it; the next scan downloads it again."
+			return check, binary
+		}
+		if err := runner.VerifyCachedSignature(cachePath, binary); err != nil {
+			check.Status, check.Detail = checkWarn, fmt.Sprintf("the signature of the cached binary %s cannot be verified: %s", cachePath, err)
+			check.Hint = "The next scan downloads the binary again."
+			return check, binary
+		}
+		check.Status, check.Detail = checkPass, fmt.Sprintf("mcp-scan %s is cached at %s", binary.Version, cachePath)
+		return check, binary
+	}
+
+	if err := runner.CheckDownload(ctx, binary); err != nil {
+		check.Status, check.Detail = checkFail, fmt.Sprintf("mcp-scan %s is not cached and cannot be downloaded: %s", binary.Version, err)
+		check.Hint = fmt.Sprintf("Allow access to the download URL, set --%s to an internal mirror or provide the binary with --%s.",
+			FlagScannerDownloadURL, FlagScannerBinary)
+		return check, binary
+	}
+	assetURL, _ := binary.AssetURL()
+	check.Status, check.Detail = checkPass, fmt.Sprintf("mcp-scan %s is not cached yet and can be downloaded from %s", binary.Version, redactURL(assetURL))
+	return check, binary
+}
+
+func checkUpstreamProxy(apiURL string) doctorCheck {
+	check := doctorCheck{Name: "Upstream proxy"}
+	req, err := http.NewRequest(http.MethodGet, apiURL, http.NoBody)
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		return check
+	}
+	upstream, err := http.ProxyFromEnvironment(req)
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		check.Hint = "Fix the proxy URL in HTTPS_PROXY, HTTP_PROXY or NO_PROXY."
+		return check
+	}
+	check.Status = checkPass
+	check.Detail = "no proxy is configured for " + apiURL
+	if upstream != nil {
+		check.Detail = fmt.Sprintf("%s is reached through %s", apiURL, upstream.Redacted())
+	}
+	return check
+}
+
+// checkCertificates sets up the CA of the CLI proxy the scanner connects through, including the
+// additional CA certificates trusted for upstream connections.
+func checkCertificates(ctx workflow.InvocationContext, scannerVersion string) doctorCheck {
+	check := doctorCheck{Name: "CA certificates"}
+	extraCertificates := os.Getenv(constants.SNYK_CA_CERTIFICATE_LOCATION_ENV)
+	if extraCertificates != "" {
+		if _, certificates, err := certs.GetExtraCaCert(extraCertificates); err != nil || len(certificates) == 0 {
+			check.Status = checkFail
+			check.Detail = fmt.Sprintf("%s=%s does not contain usable certificates", constants.SNYK_CA_CERTIFICATE_LOCATION_ENV, extraCertificates)
+			if err != nil {
+				check.Detail += ": " + err.Error()
+			}
+			check.Hint = fmt.Sprintf("Point %s to a PEM file with the CA certificates of your proxy.", constants.SNYK_CA_CERTIFICATE_LOCATION_ENV)
+			return check
+		}
+	}
+
+	caData, err := proxy.InitCA(ctx.GetConfiguration(), scannerVersion, ctx.GetEnhancedLogger())
+	if err != nil {
+		check.Status, check.Detail = checkFail, "failed to set up the proxy certificate: "+err.Error()
+		check.Hint = "Make sure the CLI cache and temp directories are writable."
+		return check
+	}
+	if removeErr := os.Remove(caData.CertFile); removeErr != nil {
+		ctx.GetEnhancedLogger().Debug().Err(removeErr).Msg("Failed to remove doctor certificate file")
+	}
+
+	check.Status = checkPass
+	check.Detail = "proxy certificate created"
+	if extraCertificates != "" {
+		check.Detail += ", trusting additional certificates from " + extraCertificates
+	}
+	return check
+}
+
+func renderDoctorReport(report *doctorReport) string {
+	var b strings.Builder
+	for _, check := range report.Checks {
+		fmt.Fprintf(&b, "[%s] %s", strings.ToUpper(string(check.Status)), check.Name)
+		if check.Detail != "" {
+			fmt.Fprintf(&b, ": %s", check.Detail)
+		}
+		b.WriteString("\n")
+		if check.Hint != "" {
+			fmt.Fprintf(&b, "       hint: %s\n", check.Hint)
+		}
+	}
+	if report.Passed {
+		b.WriteString("\nAll checks passed\n")
+	} else {
+		b.WriteString("\nSome checks failed\n")
+	}
+	return b.String()
+}
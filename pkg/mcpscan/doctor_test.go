@@ -0,0 +1,148 @@
+package mcpscan //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/constants"
+)
+
+const testTenantID = "0f0e8d5e-4f0a-4c8e-9a36-3f7c2b1d9e01"
+
+// newDoctorTestServer fakes the Snyk API and a scanner download mirror.
+func newDoctorTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/tenants":
+			w.Header().Set("Content-Type", "application/vnd.api+json")
+			_, _ = fmt.Fprintf(w, `{"data":[{"id":%q,"type":"tenant","attributes":{"name":"Acme","slug":"acme"}}],"jsonapi":{"version":"1.0"},"links":{}}`, testTenantID)
+		case "/hidden/tenants/" + testTenantID + "/mcp-scan/push-key":
+			_, _ = fmt.Fprintf(w, `{"client_id":%q}`, testUUID)
+		default:
+			if strings.HasPrefix(r.URL.Path, "/mirror/") {
+				// Only the first byte is requested to check that the binary can be downloaded
+				assert.Equal(t, "bytes=0-0", r.Header.Get("Range"))
+				http.ServeContent(w, r, "", time.Time{}, strings.NewReader("binary"))
+				return
+			}
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newDoctorTestContext(t *testing.T, serverURL string, whoamiErr error) (*mocks.MockInvocationContext, *mocks.MockUserInterface) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	logger := zerolog.Nop()
+	t.Setenv(constants.SNYK_CA_CERTIFICATE_LOCATION_ENV, "")
+
+	config := configuration.NewWithOpts()
+	config.Set(FlagExperimental, true)
+	config.Set(configuration.API_URL, serverURL)
+	config.Set(configuration.CACHE_PATH, t.TempDir())
+	config.Set(configuration.TEMP_DIR_PATH, t.TempDir())
+	config.Set(FlagScannerDownloadURL, serverURL+"/mirror/{asset}")
+
+	engine := mocks.NewMockEngine(ctrl)
+	engine.EXPECT().InvokeWithConfig(gomock.Any(), gomock.Any()).Return(nil, whoamiErr).AnyTimes()
+	networkAccess := mocks.NewMockNetworkAccess(ctrl)
+	networkAccess.EXPECT().GetHttpClient().Return(http.DefaultClient).AnyTimes()
+	userInterface := mocks.NewMockUserInterface(ctrl)
+
+	ictx := mocks.NewMockInvocationContext(ctrl)
+	ictx.EXPECT().GetEnhancedLogger().Return(&logger).AnyTimes()
+	ictx.EXPECT().GetConfiguration().Return(config).AnyTimes()
+	ictx.EXPECT().GetEngine().Return(engine).AnyTimes()
+	ictx.EXPECT().GetNetworkAccess().Return(networkAccess).AnyTimes()
+	ictx.EXPECT().GetUserInterface().Return(userInterface).AnyTimes()
+	ictx.EXPECT().Context().Return(t.Context()).AnyTimes()
+	return ictx, userInterface
+}
+
+func checkStatuses(report *doctorReport) map[string]checkStatus {
+	statuses := map[string]checkStatus{}
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+	return statuses
+}
+
+func TestDoctorWorkflow_Passes(t *testing.T) {
+	server := newDoctorTestServer(t)
+	ictx, _ := newDoctorTestContext(t, server.URL, nil)
+
+	output, err := DoctorWorkflow(ictx, nil)
+	require.NoError(t, err)
+	require.Len(t, output, 1)
+	assert.Equal(t, contentTypeText, output[0].GetContentType())
+	assert.Contains(t, output[0].GetPayload(), "Authentication")
+
+	ictx.GetConfiguration().Set(FlagJSON, true)
+	output, err = DoctorWorkflow(ictx, nil)
+	require.NoError(t, err)
+	require.Len(t, output, 1)
+
+	var report doctorReport
+	require.NoError(t, json.Unmarshal(output[0].GetPayload().([]byte), &report))
+	assert.True(t, report.Passed)
+	assert.Equal(t, map[string]checkStatus{
+		"Authentication":    checkPass,
+		"Tenants":           checkPass,
+		"Push key":          checkPass,
+		"Analysis endpoint": checkPass,
+		"Control endpoint":  checkPass,
+		"Scanner binary":    checkPass,
+		"Upstream proxy":    checkPass,
+		"CA certificates":   checkPass,
+	}, checkStatuses(&report))
+	assert.NotContains(t, string(output[0].GetPayload().([]byte)), testUUID)
+}
+
+func TestDoctorWorkflow_Fails(t *testing.T) {
+	server := newDoctorTestServer(t)
+	ictx, userInterface := newDoctorTestContext(t, server.URL, fmt.Errorf("missing token"))
+	ictx.GetConfiguration().Set(FlagScannerBinary, writeTempBinary(t))
+
+	var printed string
+	userInterface.EXPECT().Output(gomock.Any()).DoAndReturn(func(output string) error {
+		printed = output
+		return nil
+	})
+
+	_, err := DoctorWorkflow(ictx, nil)
+	require.Error(t, err)
+
+	report := runDoctor(ictx)
+	assert.False(t, report.Passed)
+	statuses := checkStatuses(report)
+	assert.Equal(t, checkFail, statuses["Authentication"])
+	assert.Equal(t, checkSkip, statuses["Tenants"])
+	assert.Equal(t, checkSkip, statuses["Push key"])
+	assert.Equal(t, checkFail, statuses["Scanner binary"])
+	assert.Contains(t, printed, "[FAIL] Authentication")
+	assert.Contains(t, printed, "hint: Run `snyk auth`")
+}
+
+func writeTempBinary(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mcp-scan")
+	require.NoError(t, os.WriteFile(path, []byte("not the pinned binary"), 0o700))
+	return path
+}
@@ -0,0 +1,164 @@
+package mcpscan
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/snyk/go-application-framework/pkg/configuration"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/cache"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/constants"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/runner"
+)
+
+// redacted replaces secrets in the dry-run report.
+const redacted = "***"
+
+// clientIDHeaderPrefix starts the control server header carrying the client ID.
+const clientIDHeaderPrefix = "x-client-id: "
+
+// dryRunReport describes the scanner invocation a scan would perform.
+type dryRunReport struct {
+	TenantID          string        `json:"tenantId,omitempty"`
+	ClientID          string        `json:"clientId,omitempty"`
+	AnalysisURL       string        `json:"analysisUrl"`
+	ControlServerURL  string        `json:"controlServerUrl,omitempty"`
+	ControlIdentifier string        `json:"controlIdentifier,omitempty"`
+	Scanner           dryRunScanner `json:"scanner"`
+	Proxy             dryRunProxy   `json:"proxy"`
+	Args              []string      `json:"args"`
+}
+
+type dryRunScanner struct {
+	Version         string   `json:"version"`
+	Path            string   `json:"path"`
+	DownloadURL     string   `json:"downloadUrl,omitempty"`
+	DownloadHeaders []string `json:"downloadHeaders,omitempty"`
+	Timeout         string   `json:"timeout,omitempty"`
+	Sandbox         bool     `json:"sandbox"`
+}
+
+type dryRunProxy struct {
+	// Scanner is the proxy URL the scanner is configured with; its port is chosen when the scan starts.
+	Scanner string `json:"scanner"`
+	// Upstream is the proxy the CLI forwards the scanner's traffic to, if any.
+	Upstream string `json:"upstream,omitempty"`
+	// ExtraCACertificates is the file with additional CA certificates trusted by the proxy.
+	ExtraCACertificates string `json:"extraCaCertificates,omitempty"`
+}
+
+// newDryRunReport resolves what a scan would run without downloading or starting the scanner.
+// The client ID, the proxy password and download headers are redacted.
+func newDryRunReport(config configuration.Configuration, binary runner.Binary, tenantID, clientID string, args []string) (*dryRunReport, error) {
+	report := &dryRunReport{
+		TenantID:          tenantID,
+		ClientID:          redactClientID(clientID),
+		AnalysisURL:       flagValue(args, "--analysis-url"),
+		ControlServerURL:  flagValue(args, "--control-server"),
+		ControlIdentifier: flagValue(args, "--control-identifier"),
+		Scanner: dryRunScanner{
+			Version: binary.Version,
+			Path:    binary.LocalPath,
+			Sandbox: binary.Sandbox,
+		},
+		Proxy: dryRunProxy{
+			Scanner: fmt.Sprintf("http://%s:%s@127.0.0.1:<port>", proxy.PROXY_USERNAME, redacted),
+		},
+	}
+
+	if binary.Timeout > 0 {
+		report.Scanner.Timeout = binary.Timeout.String()
+	}
+	if binary.LocalPath == "" {
+		report.Scanner.Path = filepath.Join(cache.Dir(config), binary.Asset)
+		downloadURL, err := binary.AssetURL()
+		if err != nil {
+			return nil, err
+		}
+		report.Scanner.DownloadURL = redactURL(downloadURL)
+		for _, header := range binary.DownloadHeaders {
+			name, _, _ := strings.Cut(header, ":")
+			report.Scanner.DownloadHeaders = append(report.Scanner.DownloadHeaders, strings.TrimSpace(name)+": "+redacted)
+		}
+	}
+
+	if request, err := http.NewRequest(http.MethodGet, config.GetString(configuration.API_URL), http.NoBody); err == nil {
+		if upstream, proxyErr := http.ProxyFromEnvironment(request); proxyErr == nil && upstream != nil {
+			report.Proxy.Upstream = upstream.Redacted()
+		}
+	}
+	report.Proxy.ExtraCACertificates = os.Getenv(constants.SNYK_CA_CERTIFICATE_LOCATION_ENV)
+
+	report.Args = append([]string{report.Scanner.Path}, args...)
+	for i, arg := range report.Args {
+		if strings.HasPrefix(strings.ToLower(arg), clientIDHeaderPrefix) {
+			report.Args[i] = clientIDHeaderPrefix + redactClientID(strings.TrimSpace(arg[len(clientIDHeaderPrefix):]))
+		}
+	}
+	return report, nil
+}
+
+func renderDryRun(report *dryRunReport) string {
+	var b strings.Builder
+	b.WriteString("Dry run, mcp-scan is neither downloaded nor run.\n\n")
+	rows := [][2]string{
+		{"Tenant ID", report.TenantID},
+		{"Client ID", report.ClientID},
+		{"Analysis URL", report.AnalysisURL},
+		{"Control server URL", report.ControlServerURL},
+		{"Control identifier", report.ControlIdentifier},
+		{"Scanner version", report.Scanner.Version},
+		{"Scanner binary", report.Scanner.Path},
+		{"Download URL", report.Scanner.DownloadURL},
+		{"Download headers", strings.Join(report.Scanner.DownloadHeaders, ", ")},
+		{"Timeout", report.Scanner.Timeout},
+		{"Sandbox", fmt.Sprintf("%t", report.Scanner.Sandbox)},
+		{"Scanner proxy", report.Proxy.Scanner},
+		{"Upstream proxy", report.Proxy.Upstream},
+		{"Extra CA certificates", report.Proxy.ExtraCACertificates},
+	}
+	for _, row := range rows {
+		if row[1] != "" {
+			fmt.Fprintf(&b, "%-22s %s\n", row[0]+":", row[1])
+		}
+	}
+	b.WriteString("\nCommand:\n")
+	for _, arg := range report.Args {
+		fmt.Fprintf(&b, "  %s\n", arg)
+	}
+	return b.String()
+}
+
+// flagValue returns the value following the last occurrence of flag in args.
+func flagValue(args []string, flag string) string {
+	value := ""
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == flag {
+			value = args[i+1]
+		}
+	}
+	return value
+}
+
+// redactClientID keeps the last four characters of a client ID so runs can be told apart.
+func redactClientID(clientID string) string {
+	const visible = 4
+	if len(clientID) <= visible {
+		return strings.Repeat("*", len(clientID))
+	}
+	return redacted + clientID[len(clientID)-visible:]
+}
+
+// redactURL hides the password of credentials embedded in a URL.
+func redactURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return parsed.Redacted()
+}
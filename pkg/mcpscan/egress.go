@@ -0,0 +1,92 @@
+package mcpscan
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/snyk/go-application-framework/pkg/configuration"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/errors"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/policy"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/results"
+)
+
+// resolveEgressPolicy combines --egress and --allow-host with the egress section of the policy
+// file; the flags take precedence over the file's mode. Without an explicit mode, egress is
+// blocked as soon as hosts are allowed and otherwise reported with a warning; "off" turns the
+// policy off. The Snyk API, the additional authenticated URLs and the scanner download mirror are
+// always allowed.
+func resolveEgressPolicy(config configuration.Configuration, fileEgress policy.Egress) (*proxy.EgressPolicy, error) {
+	allowHosts := append([]string(nil), fileEgress.Allow...)
+	for _, value := range config.GetStringSlice(FlagAllowHost) {
+		for _, host := range strings.Split(value, ",") {
+			if host = strings.TrimSpace(host); host != "" {
+				allowHosts = append(allowHosts, host)
+			}
+		}
+	}
+
+	modeValue := config.GetString(FlagEgress)
+	if modeValue == "" {
+		modeValue = fileEgress.Mode
+	}
+	mode := proxy.EgressWarn
+	switch {
+	case modeValue != "":
+		var err error
+		if mode, err = proxy.ParseEgressMode(modeValue); err != nil {
+			return nil, errors.NewInvalidFlagValueError(fmt.Sprintf("Invalid --%s: %s", FlagEgress, err)).SnykError
+		}
+	case len(allowHosts) > 0:
+		mode = proxy.EgressBlock
+	}
+
+	egress, err := proxy.NewEgressPolicy(mode, append(snykHosts(config), allowHosts...))
+	if err != nil {
+		return nil, errors.NewInvalidFlagValueError(fmt.Sprintf("Invalid --%s: %s", FlagAllowHost, err)).SnykError
+	}
+	return egress, nil
+}
+
+// snykHosts returns the URLs of the Snyk API and the configured mirrors a scan talks to.
+func snykHosts(config configuration.Configuration) []string {
+	candidates := append([]string{config.GetString(configuration.API_URL)}, config.GetStringSlice(configuration.AUTHENTICATION_ADDITIONAL_URLS)...)
+	candidates = append(candidates, config.GetString(FlagScannerDownloadURL))
+
+	var hosts []string
+	for _, candidate := range candidates {
+		if parsed, err := url.Parse(candidate); err == nil && parsed.Hostname() != "" && !strings.ContainsAny(parsed.Host, "{}") {
+			hosts = append(hosts, parsed.Scheme+"://"+parsed.Host)
+		}
+	}
+	return hosts
+}
+
+// newEgressReport describes the destinations the egress policy denied, nil if there were none.
+func newEgressReport(egress *proxy.EgressPolicy) *results.EgressReport {
+	denied := egress.Denied()
+	if len(denied) == 0 {
+		return nil
+	}
+	report := &results.EgressReport{Mode: string(egress.Mode())}
+	for _, destination := range denied {
+		report.Denied = append(report.Denied, results.DeniedHost{Host: destination.Host, Attempts: destination.Attempts})
+	}
+	return report
+}
+
+// egressHint names the destinations blocked by the egress policy for the error of a failed scan,
+// as blocking a destination the scanner depends on makes it fail.
+func egressHint(egress *proxy.EgressPolicy) string {
+	denied := egress.Denied()
+	if egress.Mode() != proxy.EgressBlock || len(denied) == 0 {
+		return ""
+	}
+	hosts := make([]string, 0, len(denied))
+	for _, destination := range denied {
+		hosts = append(hosts, destination.Host)
+	}
+	return fmt.Sprintf(". The egress policy blocked connections to %s; allow them with --%s if they are expected.", strings.Join(hosts, ", "), FlagAllowHost)
+}
@@ -0,0 +1,73 @@
+package mcpscan //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"testing"
+
+	"github.com/snyk/error-catalog-golang-public/snyk_errors"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/policy"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
+)
+
+func TestResolveEgressPolicy_Mode(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		allowHosts []string
+		fileEgress policy.Egress
+		expected   proxy.EgressMode
+	}{
+		{name: "warn by default", expected: proxy.EgressWarn},
+		{name: "explicit off", mode: "off", expected: proxy.EgressOff},
+		{name: "off from the policy file", fileEgress: policy.Egress{Mode: "off"}, expected: proxy.EgressOff},
+		{name: "block when hosts are allowed", allowHosts: []string{"registry.npmjs.org"}, expected: proxy.EgressBlock},
+		{name: "block when the policy file allows hosts", fileEgress: policy.Egress{Allow: []string{"registry.npmjs.org"}}, expected: proxy.EgressBlock},
+		{name: "mode from the policy file", fileEgress: policy.Egress{Mode: "warn"}, expected: proxy.EgressWarn},
+		{name: "flag overrides the policy file", mode: "off", fileEgress: policy.Egress{Mode: "block", Allow: []string{"pypi.org"}}, expected: proxy.EgressOff},
+		{name: "explicit warn with hosts", mode: "warn", allowHosts: []string{"pypi.org,files.pythonhosted.org"}, expected: proxy.EgressWarn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := configuration.NewWithOpts()
+			config.Set(FlagEgress, tt.mode)
+			config.Set(FlagAllowHost, tt.allowHosts)
+
+			egress, err := resolveEgressPolicy(config, tt.fileEgress)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, egress.Mode())
+			assert.Nil(t, newEgressReport(egress))
+			assert.Empty(t, egressHint(egress))
+		})
+	}
+}
+
+func TestResolveEgressPolicy_Invalid(t *testing.T) {
+	config := configuration.NewWithOpts()
+	config.Set(FlagEgress, "deny")
+	_, err := resolveEgressPolicy(config, policy.Egress{})
+	var snykErr snyk_errors.Error
+	require.ErrorAs(t, err, &snykErr)
+	assert.Contains(t, snykErr.Detail, "--egress")
+
+	config = configuration.NewWithOpts()
+	config.Set(FlagAllowHost, []string{"example.com/path"})
+	_, err = resolveEgressPolicy(config, policy.Egress{})
+	require.ErrorAs(t, err, &snykErr)
+	assert.Contains(t, snykErr.Detail, "--allow-host")
+}
+
+func TestSnykHosts(t *testing.T) {
+	config := configuration.NewWithOpts()
+	config.Set(configuration.API_URL, "https://api.eu.snyk.io")
+	config.Set(configuration.AUTHENTICATION_ADDITIONAL_URLS, []string{"https://snyk-mirror.example.com:8443/api"})
+	config.Set(FlagScannerDownloadURL, "https://artifacts.example.com/mcp-scan/{version}/{asset}")
+
+	assert.Equal(t, []string{
+		"https://api.eu.snyk.io",
+		"https://snyk-mirror.example.com:8443",
+		"https://artifacts.example.com",
+	}, snykHosts(config))
+}
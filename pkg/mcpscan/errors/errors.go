@@ -38,3 +38,31 @@ func NewInvalidTenantIDError() *McpScanError {
 func NewInvalidClientIDError() *McpScanError {
 	return &McpScanError{SnykError: snyk_common_errors.NewUnauthorisedError("Invalid client ID")}
 }
+
+func NewInvalidFlagValueError(msg string) *McpScanError {
+	return &McpScanError{SnykError: cli_errors.NewInvalidFlagOptionError(msg)}
+}
+
+func NewScannerFailedError(msg string, cause error) *McpScanError {
+	return &McpScanError{SnykError: cli_errors.NewGeneralCLIFailureError(msg, snyk_errors.WithCause(cause))}
+}
+
+func NewNothingToScanError() *McpScanError {
+	return &McpScanError{SnykError: cli_errors.NewNoSupportedFilesFoundError("No MCP server configurations were found to scan.")}
+}
+
+func NewInvalidPolicyError(msg string) *McpScanError {
+	return &McpScanError{SnykError: cli_errors.NewValidationFailureError(msg)}
+}
+
+func NewScannerUnavailableError(msg string, cause error) *McpScanError {
+	return &McpScanError{SnykError: snyk_common_errors.NewRequirementsNotMetError(msg, snyk_errors.WithCause(cause))}
+}
+
+func NewCorruptCacheError(msg string) *McpScanError {
+	return &McpScanError{SnykError: cli_errors.NewGeneralCLIFailureError(msg)}
+}
+
+func NewDiagnosticsFailedError(msg string) *McpScanError {
+	return &McpScanError{SnykError: cli_errors.NewGeneralCLIFailureError(msg)}
+}
@@ -1,6 +1,7 @@
 package errors_test
 
 import (
+	stderrors "errors"
 	"testing"
 
 	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/errors"
@@ -60,6 +61,76 @@ func TestNewInvalidClientIDError(t *testing.T) {
 	}
 }
 
+func TestNewInvalidFlagValueError(t *testing.T) {
+	err := errors.NewInvalidFlagValueError("invalid value")
+
+	if err == nil {
+		t.Fatal(errNonNil)
+	}
+
+	if err.SnykError.Detail != "invalid value" {
+		t.Errorf("expected detail to be %q, got %q", "invalid value", err.SnykError.Detail)
+	}
+}
+
+func TestNewScannerFailedError(t *testing.T) {
+	cause := stderrors.New("exit status 139")
+	err := errors.NewScannerFailedError("scanner crashed", cause)
+
+	if err == nil {
+		t.Fatal(errNonNil)
+	}
+
+	if err.SnykError.Error() == "" {
+		t.Error(errNonEmptyMsg)
+	}
+
+	if !stderrors.Is(err.SnykError, cause) {
+		t.Error("expected error to wrap its cause")
+	}
+}
+
+func TestNewNothingToScanError(t *testing.T) {
+	err := errors.NewNothingToScanError()
+
+	if err == nil {
+		t.Fatal(errNonNil)
+	}
+
+	if err.SnykError.ErrorCode != "SNYK-CLI-0008" {
+		t.Errorf("expected no supported files error code, got %q", err.SnykError.ErrorCode)
+	}
+}
+
+func TestNewInvalidPolicyError(t *testing.T) {
+	err := errors.NewInvalidPolicyError("ignore rule 1 is missing a reason")
+
+	if err == nil {
+		t.Fatal(errNonNil)
+	}
+
+	if err.SnykError.Detail != "ignore rule 1 is missing a reason" {
+		t.Errorf("expected detail to be preserved, got %q", err.SnykError.Detail)
+	}
+
+	if err.SnykError.ErrorCode != "SNYK-CLI-0010" {
+		t.Errorf("expected validation failure error code, got %q", err.SnykError.ErrorCode)
+	}
+}
+
+func TestNewScannerUnavailableError(t *testing.T) {
+	cause := stderrors.New("dial tcp: lookup github.com: no such host")
+	err := errors.NewScannerUnavailableError("mcp-scan binary is not available", cause)
+
+	if err == nil {
+		t.Fatal(errNonNil)
+	}
+
+	if !stderrors.Is(err.SnykError, cause) {
+		t.Error("expected error to wrap its cause")
+	}
+}
+
 func TestMcpScanErrorType(t *testing.T) {
 	err := errors.NewUnauthorizedError("test")
 
@@ -67,3 +138,27 @@ func TestMcpScanErrorType(t *testing.T) {
 		t.Error("expected error to be of type *McpScanError")
 	}
 }
+
+func TestNewCorruptCacheError(t *testing.T) {
+	err := errors.NewCorruptCacheError("cached binary does not match")
+
+	if err == nil {
+		t.Fatal(errNonNil)
+	}
+
+	if err.SnykError.Detail != "cached binary does not match" {
+		t.Errorf("expected detail to be preserved, got %q", err.SnykError.Detail)
+	}
+}
+
+func TestNewDiagnosticsFailedError(t *testing.T) {
+	err := errors.NewDiagnosticsFailedError("2 checks failed")
+
+	if err == nil {
+		t.Fatal(errNonNil)
+	}
+
+	if err.SnykError.Detail != "2 checks failed" {
+		t.Errorf("expected detail to be preserved, got %q", err.SnykError.Detail)
+	}
+}
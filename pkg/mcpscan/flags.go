@@ -1,6 +1,10 @@
 package mcpscan
 
-import "github.com/spf13/pflag"
+import (
+	"github.com/spf13/pflag"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
+)
 
 const (
 	flagSetName      = "mcp-scan"
@@ -10,6 +14,26 @@ const (
 	FlagJSON         = "json"
 	FlagSkills       = "skills"
 	FlagNoUpload     = "no-upload"
+	FlagSarif        = "sarif"
+	FlagSarifFile    = "sarif-file-output"
+
+	FlagSeverityThreshold = "severity-threshold"
+	FlagFailOn            = "fail-on"
+	FlagPolicyPath        = "policy-path"
+	FlagBaseline          = "baseline"
+	FlagScannerBinary     = "scanner-binary"
+	FlagScannerVersion    = "scanner-version"
+	FlagTimeout           = "timeout"
+	FlagPassEnv           = "pass-env"
+	FlagSandbox           = "sandbox"
+	FlagDryRun            = "dry-run"
+	FlagAllowHost         = "allow-host"
+	FlagEgress            = "egress"
+	FlagHARFile           = "har-file"
+	FlagHARBodyLimit      = "har-body-limit"
+
+	FlagScannerDownloadURL    = "scanner-download-url"
+	FlagScannerDownloadHeader = "scanner-download-header"
 )
 
 func getFlagSet() *pflag.FlagSet {
@@ -21,5 +45,34 @@ func getFlagSet() *pflag.FlagSet {
 	flagSet.String(FlagSkills, "", "Scan skills beyond mcp servers. Can be used as a boolean flag or with a folder path.")
 	flagSet.Lookup(FlagSkills).NoOptDefVal = "true"
 	flagSet.Bool(FlagNoUpload, false, "Do not upload the scan results to the Evo")
+	flagSet.Bool(FlagSarif, false, "Output in SARIF format")
+	flagSet.String(FlagSarifFile, "", "Save the SARIF output to the given file path")
+	flagSet.String(FlagSeverityThreshold, "", "Only report issues of the given severity or higher (low|medium|high|critical)")
+	flagSet.String(FlagFailOn, "", "Only fail when there are issues of the given severity or higher (low|medium|high|critical), defaults to the severity threshold")
+	flagSet.String(FlagPolicyPath, "", "Path to the policy file with ignored findings, defaults to .snyk-mcp.yaml in the current directory")
+	flagSet.String(FlagBaseline, "", "Path to the --json results of a previous run; only new issues are reported as failures")
+	flagSet.String(FlagScannerBinary, "", "Path to a pre-provisioned mcp-scan binary to use instead of downloading it")
+	flagSet.String(FlagTimeout, "", "Maximum duration of the scan, e.g. 90s or 10m; a plain number is read as seconds")
+	flagSet.StringArray(FlagPassEnv, nil, "Environment variables passed to mcp-scan and the MCP servers it starts in addition to a minimal default set, e.g. AWS_PROFILE or 'NVM_*'; can be repeated or comma separated")
+	flagSet.Bool(FlagDryRun, false, "Print the resolved tenant, client ID, URLs, proxy settings and scanner command line without downloading or running mcp-scan")
+	flagSet.StringArray(FlagAllowHost, nil, "Host the scanner and the MCP servers it starts may connect to besides the Snyk API, e.g. registry.npmjs.org, "+
+		"*.example.com or example.com:8443; can be repeated or comma separated")
+	flagSet.String(FlagEgress, "", "What to do with connections to hosts that are not allowed (off|warn|block), defaults to block when hosts are allowed "+
+		"with --allow-host or in the policy file and to warn otherwise; off disables the check")
+	flagSet.String(FlagHARFile, "", "Record all requests and responses passing through the proxy to the given HAR 1.2 file, "+
+		"with credentials and the client ID redacted")
+	flagSet.Int(FlagHARBodyLimit, proxy.DefaultHARBodyLimit, "Number of bytes of each request and response body recorded with --har-file, 0 records headers only")
+	flagSet.Bool(FlagSandbox, false, "Linux only: run mcp-scan and the MCP servers it starts with a read-only filesystem outside a scratch directory, resource limits and no privilege escalation")
+	flagSet.String(FlagScannerVersion, "", "Version of mcp-scan to run, must be one of the versions pinned by this CLI, defaults to "+MCPScanBinaryVersion)
+	flagSet.String(FlagScannerDownloadURL, "", "Download URL template for the mcp-scan binary, supports {version}, {tag} and {asset} placeholders")
+	flagSet.StringArray(FlagScannerDownloadHeader, nil, "Additional \"Name: value\" header sent when downloading the mcp-scan binary, can be repeated")
+	return flagSet
+}
+
+func getCacheFlagSet() *pflag.FlagSet {
+	flagSet := pflag.NewFlagSet(flagSetName+" cache", pflag.ExitOnError)
+	flagSet.Bool(FlagExperimental, false, "This is an experiment feature that will contain breaking changes in future revisions")
+	flagSet.Bool(FlagJSON, false, "Output in JSON format")
+	flagSet.String(FlagScannerVersion, "", "Version of mcp-scan whose binary is kept by prune, defaults to "+MCPScanBinaryVersion)
 	return flagSet
 }
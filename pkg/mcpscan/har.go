@@ -0,0 +1,40 @@
+package mcpscan
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/snyk/go-application-framework/pkg/configuration"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/errors"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
+)
+
+// resolveHARBodyLimit returns how many bytes of each body --har-file captures.
+func resolveHARBodyLimit(config configuration.Configuration) (int64, error) {
+	if !config.IsSet(FlagHARBodyLimit) {
+		return proxy.DefaultHARBodyLimit, nil
+	}
+	limit := config.GetInt(FlagHARBodyLimit)
+	if limit < 0 {
+		return 0, errors.NewInvalidFlagValueError(fmt.Sprintf("Invalid --%s: %d, expected a number of bytes or 0 to capture headers only", FlagHARBodyLimit, limit)).SnykError
+	}
+	return int64(limit), nil
+}
+
+// writeHARFile writes the traffic captured by the proxy to path. The file is only readable by the
+// user as bodies may contain data the redaction does not know about.
+func writeHARFile(path string, wrapperProxy *proxy.WrapperProxy) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create HAR file %s: %w", path, err)
+	}
+	if err = wrapperProxy.WriteHAR(file); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write HAR file %s: %w", path, err)
+	}
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("failed to write HAR file %s: %w", path, err)
+	}
+	return nil
+}
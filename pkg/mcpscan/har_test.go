@@ -0,0 +1,30 @@
+package mcpscan //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"testing"
+
+	"github.com/snyk/error-catalog-golang-public/snyk_errors"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
+)
+
+func TestResolveHARBodyLimit(t *testing.T) {
+	config := configuration.NewWithOpts()
+	limit, err := resolveHARBodyLimit(config)
+	require.NoError(t, err)
+	assert.Equal(t, int64(proxy.DefaultHARBodyLimit), limit)
+
+	config.Set(FlagHARBodyLimit, 0)
+	limit, err = resolveHARBodyLimit(config)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), limit)
+
+	config.Set(FlagHARBodyLimit, -1)
+	_, err = resolveHARBodyLimit(config)
+	var snykErr snyk_errors.Error
+	require.ErrorAs(t, err, &snykErr)
+	assert.Contains(t, snykErr.Detail, "--har-body-limit")
+}
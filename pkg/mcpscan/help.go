@@ -0,0 +1,57 @@
+package mcpscan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/manifest"
+)
+
+// renderHelp describes the wrapper flags and the options of the given scanner version that can
+// be forwarded to it. It only needs the embedded manifest, so help works without downloading the
+// scanner.
+func renderHelp(wrapperFlags *pflag.FlagSet, scannerManifest *manifest.Manifest, version string) string {
+	var b strings.Builder
+	b.WriteString("Usage: snyk mcp-scan --experimental [FLAGS] [--] [CONFIG_FILE...]\n\n")
+	b.WriteString("Scans the MCP servers and agent skills configured on this machine, or in the given\n")
+	b.WriteString("configuration files, for security issues such as prompt injections and toxic flows.\n\n")
+	b.WriteString("Commands:\n")
+	b.WriteString("  help                 Show this help\n")
+	b.WriteString("  cache <action>       Manage cached scanner binaries (list|verify|prune|clear)\n")
+	b.WriteString("  doctor               Check authentication, connectivity, proxy and scanner setup\n\n")
+
+	b.WriteString("Flags:\n")
+	b.WriteString(wrapperFlags.FlagUsages())
+
+	var rows [][2]string
+	for _, option := range scannerManifest.Options[version] {
+		if wrapperFlags.Lookup(option.Name) != nil || managedScannerOptions[option.Name] {
+			continue
+		}
+		name := "--" + option.Name
+		switch {
+		case option.Value == "":
+		case option.OptionalValue:
+			name += "[=" + option.Value + "]"
+		default:
+			name += " " + option.Value
+		}
+		rows = append(rows, [2]string{name, option.Usage})
+	}
+	if len(rows) > 0 {
+		fmt.Fprintf(&b, "\nScanner options (mcp-scan %s), forwarded to the scanner:\n", version)
+		width := 0
+		for _, row := range rows {
+			width = max(width, len(row[0]))
+		}
+		for _, row := range rows {
+			fmt.Fprintf(&b, "      %-*s   %s\n", width, row[0], row[1])
+		}
+	}
+
+	b.WriteString("\nRun `snyk auth` before scanning, or provide --client-id to upload the results without\n")
+	b.WriteString("authentication. --no-upload skips uploading the results and requires `snyk auth`.\n")
+	return b.String()
+}
@@ -0,0 +1,25 @@
+package mcpscan //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/manifest"
+)
+
+func TestRenderHelp(t *testing.T) {
+	scannerManifest, err := manifest.Load()
+	require.NoError(t, err)
+
+	help := renderHelp(getFlagSet(), scannerManifest, MCPScanBinaryVersion)
+
+	for _, flag := range []string{FlagTenantID, FlagClientID, FlagNoUpload, FlagSkills, FlagExperimental} {
+		assert.Contains(t, help, "--"+flag)
+	}
+	assert.Contains(t, help, "Scanner options (mcp-scan "+MCPScanBinaryVersion+")")
+	assert.Contains(t, help, "--storage-file PATH")
+	assert.NotContains(t, help, "--control-server")
+	assert.NotContains(t, help, "--analysis-url")
+}
@@ -0,0 +1,169 @@
+// Package manifest describes the scanner releases the extension can run: for every pinned
+// version, the release asset and checksum of each supported platform and the options of its
+// scan command. Only the platforms listed in manifest.json are supported. Linux on arm64 and
+// musl based distributions ("linux/arm64", "linux/amd64/musl") are detected, but are refused
+// until assets for them are published and their checksums are pinned in the manifest.
+package manifest
+
+import (
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed manifest.json
+var embeddedManifest []byte
+
+var (
+	// ErrUnknownVersion is returned when a scanner version is not pinned in the manifest.
+	ErrUnknownVersion = errors.New("unknown scanner version")
+	// ErrUnsupportedPlatform is returned when a pinned version has no asset for the platform.
+	ErrUnsupportedPlatform = errors.New("unsupported platform")
+)
+
+// Asset is a scanner release asset and the SHA-256 checksum it must match.
+type Asset struct {
+	Name     string `json:"asset"`
+	Checksum string `json:"sha256"`
+}
+
+// Release maps platforms, formatted as "os/arch" or "os/arch/libc", to the asset built for them.
+type Release map[string]Asset
+
+// Option is a command line option of the scanner's scan command.
+type Option struct {
+	// Name is the long name of the option, without leading dashes.
+	Name string `json:"name"`
+	// Value names the value the option takes; empty for switches.
+	Value string `json:"value,omitempty"`
+	// OptionalValue is set when the value may be omitted; it can then only be given as --name=value.
+	OptionalValue bool `json:"optionalValue,omitempty"`
+	// Usage describes the option for the help output.
+	Usage string `json:"usage"`
+}
+
+// Manifest lists the pinned scanner releases and the options they support by version.
+type Manifest struct {
+	Releases map[string]Release  `json:"versions"`
+	Options  map[string][]Option `json:"options"`
+}
+
+// Load returns the manifest embedded in the extension.
+func Load() (*Manifest, error) {
+	return Parse(embeddedManifest)
+}
+
+// Parse decodes and validates a manifest document.
+func Parse(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse scanner manifest: %w", err)
+	}
+	if len(m.Releases) == 0 {
+		return nil, fmt.Errorf("scanner manifest does not list any versions")
+	}
+	for version, release := range m.Releases {
+		for platform, asset := range release {
+			if asset.Name == "" {
+				return nil, fmt.Errorf("scanner manifest entry %s %s is missing an asset name", version, platform)
+			}
+			if checksum, err := hex.DecodeString(asset.Checksum); err != nil || len(checksum) != 32 {
+				return nil, fmt.Errorf("scanner manifest entry %s %s has an invalid sha256 checksum", version, platform)
+			}
+		}
+		if _, ok := m.Options[version]; !ok {
+			return nil, fmt.Errorf("scanner manifest does not list the options of version %s", version)
+		}
+	}
+	for version, options := range m.Options {
+		if _, ok := m.Releases[version]; !ok {
+			return nil, fmt.Errorf("scanner manifest lists options of unknown version %s", version)
+		}
+		for _, option := range options {
+			if option.Name == "" || strings.HasPrefix(option.Name, "-") {
+				return nil, fmt.Errorf("scanner manifest lists an invalid option name %q for version %s", option.Name, version)
+			}
+		}
+	}
+	return &m, nil
+}
+
+// Platforms returns the platforms the release has assets for, sorted by name.
+func (r Release) Platforms() []string {
+	platforms := make([]string, 0, len(r))
+	for platform := range r {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
+// Versions returns the pinned scanner versions, oldest first.
+func (m *Manifest) Versions() []string {
+	versions := make([]string, 0, len(m.Releases))
+	for version := range m.Releases {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) < 0
+	})
+	return versions
+}
+
+// Release returns the release of the given scanner version.
+func (m *Manifest) Release(version string) (Release, error) {
+	release, ok := m.Releases[version]
+	if !ok {
+		return nil, fmt.Errorf("%w %q, available versions: %s", ErrUnknownVersion, version, strings.Join(m.Versions(), ", "))
+	}
+	return release, nil
+}
+
+// Resolve returns the asset of the given scanner version for the platform.
+func (m *Manifest) Resolve(version string, platform Platform) (Asset, error) {
+	release, err := m.Release(version)
+	if err != nil {
+		return Asset{}, err
+	}
+	asset, ok := release[platform.String()]
+	if !ok {
+		return Asset{}, fmt.Errorf("%w %s for mcp-scan version %s, supported platforms: %s",
+			ErrUnsupportedPlatform, platform, version, strings.Join(release.Platforms(), ", "))
+	}
+	return asset, nil
+}
+
+// LookupOption returns the option of the given scanner version's scan command by its long name.
+func (m *Manifest) LookupOption(version, name string) (Option, bool) {
+	for _, option := range m.Options[version] {
+		if option.Name == name {
+			return option, true
+		}
+	}
+	return Option{}, false
+}
+
+// compareVersions orders dotted version strings numerically, falling back to a string
+// comparison for components that are not numbers.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aerr := strconv.Atoi(as[i])
+		bn, berr := strconv.Atoi(bs[i])
+		switch {
+		case aerr == nil && berr == nil && an != bn:
+			if an < bn {
+				return -1
+			}
+			return 1
+		case (aerr != nil || berr != nil) && as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+	return len(as) - len(bs)
+}
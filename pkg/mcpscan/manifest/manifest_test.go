@@ -0,0 +1,81 @@
+package manifest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/manifest"
+)
+
+const testChecksum = "06d372791ae93b5384da5c81b87e9c816ac7756c1d56810dd05329bfc10b5613"
+
+func TestLoad(t *testing.T) {
+	m, err := manifest.Load()
+	require.NoError(t, err)
+	require.NotEmpty(t, m.Versions())
+
+	asset, err := m.Resolve("0.4.2", manifest.Platform{OS: "linux", Arch: "amd64"})
+	require.NoError(t, err)
+	assert.Equal(t, "mcp-scan-0.4.2-linux-x86_64", asset.Name)
+	assert.Equal(t, testChecksum, asset.Checksum)
+
+	// No assets are published for Linux on arm64 or musl yet, so they must not resolve to any.
+	for _, platform := range []manifest.Platform{{OS: "linux", Arch: "arm64"}, {OS: "linux", Arch: "amd64", Libc: manifest.LibcMusl}} {
+		_, err = m.Resolve("0.4.2", platform)
+		assert.ErrorIs(t, err, manifest.ErrUnsupportedPlatform, platform.String())
+	}
+}
+
+func TestResolve(t *testing.T) {
+	m, err := manifest.Parse([]byte(`{"versions": {
+		"0.10.0": {"linux/amd64": {"asset": "mcp-scan-0.10.0-linux-x86_64", "sha256": "` + testChecksum + `"}},
+		"0.4.2": {"darwin/arm64": {"asset": "mcp-scan-0.4.2-macos-arm64", "sha256": "` + testChecksum + `"}}
+	}, "options": {"0.10.0": [], "0.4.2": []}}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0.4.2", "0.10.0"}, m.Versions())
+
+	asset, err := m.Resolve("0.10.0", manifest.Platform{OS: "linux", Arch: "amd64"})
+	require.NoError(t, err)
+	assert.Equal(t, "mcp-scan-0.10.0-linux-x86_64", asset.Name)
+
+	_, err = m.Resolve("0.4.2", manifest.Platform{OS: "linux", Arch: "amd64"})
+	assert.True(t, errors.Is(err, manifest.ErrUnsupportedPlatform))
+
+	_, err = m.Resolve("9.9.9", manifest.Platform{OS: "linux", Arch: "amd64"})
+	assert.True(t, errors.Is(err, manifest.ErrUnknownVersion))
+	assert.Contains(t, err.Error(), "0.4.2, 0.10.0")
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for name, doc := range map[string]string{
+		"not json":         `not json`,
+		"no versions":      `{"versions": {}}`,
+		"missing asset":    `{"versions": {"1.0.0": {"linux/amd64": {"sha256": "` + testChecksum + `"}}}}`,
+		"invalid checksum": `{"versions": {"1.0.0": {"linux/amd64": {"asset": "a", "sha256": "abc"}}}, "options": {"1.0.0": []}}`,
+		"missing options":  `{"versions": {"1.0.0": {"linux/amd64": {"asset": "a", "sha256": "` + testChecksum + `"}}}}`,
+		"unknown options":  `{"versions": {"1.0.0": {"linux/amd64": {"asset": "a", "sha256": "` + testChecksum + `"}}}, "options": {"1.0.0": [], "2.0.0": []}}`,
+		"invalid option":   `{"versions": {"1.0.0": {"linux/amd64": {"asset": "a", "sha256": "` + testChecksum + `"}}}, "options": {"1.0.0": [{"name": "--json"}]}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := manifest.Parse([]byte(doc))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLookupOption(t *testing.T) {
+	m, err := manifest.Load()
+	require.NoError(t, err)
+
+	option, ok := m.LookupOption("0.4.2", "storage-file")
+	require.True(t, ok)
+	assert.Equal(t, "PATH", option.Value)
+
+	_, ok = m.LookupOption("0.4.2", "no-such-option")
+	assert.False(t, ok)
+	_, ok = m.LookupOption("9.9.9", "storage-file")
+	assert.False(t, ok)
+}
@@ -0,0 +1,78 @@
+package manifest
+
+import (
+	"debug/elf"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// LibcMusl marks Linux platforms using the musl C library, such as Alpine.
+const LibcMusl = "musl"
+
+// libcProbes are the executables whose dynamic loader tells which C library the system runs. The
+// CLI itself comes first; a statically linked CLI has no loader, so the system shell is used then.
+var libcProbes = []string{"/proc/self/exe", "/bin/sh"}
+
+// Platform identifies the operating system and architecture a scanner asset is built for.
+// Libc is only set for Linux platforms that do not use glibc, as their binaries are not interchangeable.
+type Platform struct {
+	OS   string
+	Arch string
+	Libc string
+}
+
+// CurrentPlatform returns the platform the CLI is running on.
+func CurrentPlatform() Platform {
+	return detectPlatform(runtime.GOOS, runtime.GOARCH, libcProbes...)
+}
+
+// detectPlatform builds the platform for the given GOOS and GOARCH. On Linux, the dynamic loader of
+// the first dynamically linked probe tells musl from glibc based distributions; merely having the
+// musl loader installed next to glibc does not make a system musl based.
+func detectPlatform(goos, goarch string, probes ...string) Platform {
+	platform := Platform{OS: goos, Arch: goarch}
+	if goos != "linux" {
+		return platform
+	}
+	for _, probe := range probes {
+		interpreter, err := elfInterpreter(probe)
+		if err != nil || interpreter == "" {
+			continue
+		}
+		if strings.HasPrefix(filepath.Base(interpreter), "ld-musl-") {
+			platform.Libc = LibcMusl
+		}
+		break
+	}
+	return platform
+}
+
+// elfInterpreter returns the dynamic loader (PT_INTERP) an ELF executable requests, or an empty
+// string for statically linked executables.
+func elfInterpreter(path string) (string, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	for _, prog := range f.Progs {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+		interpreter, err := io.ReadAll(prog.Open())
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(interpreter), "\x00"), nil
+	}
+	return "", nil
+}
+
+func (p Platform) String() string {
+	if p.Libc != "" {
+		return p.OS + "/" + p.Arch + "/" + p.Libc
+	}
+	return p.OS + "/" + p.Arch
+}
@@ -0,0 +1,101 @@
+package manifest //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeELF writes a minimal 64-bit ELF executable requesting the given dynamic loader, or none if
+// interpreter is empty.
+func writeELF(t *testing.T, interpreter string) string {
+	t.Helper()
+	var progs []elf.Prog64
+	if interpreter != "" {
+		progs = append(progs, elf.Prog64{
+			Type:   uint32(elf.PT_INTERP),
+			Flags:  uint32(elf.PF_R),
+			Off:    64 + 56,
+			Filesz: uint64(len(interpreter) + 1),
+			Memsz:  uint64(len(interpreter) + 1),
+			Align:  1,
+		})
+	}
+	header := elf.Header64{
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Phoff:     64,
+		Ehsize:    64,
+		Phentsize: 56,
+		Phnum:     uint16(len(progs)),
+		Shentsize: 64,
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+
+	var buf bytes.Buffer
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, header))
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, progs))
+	if interpreter != "" {
+		buf.WriteString(interpreter + "\x00")
+	}
+	path := filepath.Join(t.TempDir(), "exe")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+	return path
+}
+
+func TestDetectPlatform(t *testing.T) {
+	glibc := writeELF(t, "/lib64/ld-linux-x86-64.so.2")
+	musl := writeELF(t, "/lib/ld-musl-aarch64.so.1")
+	static := writeELF(t, "")
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	tests := []struct {
+		name, goos, goarch string
+		probes             []string
+		want               string
+	}{
+		{name: "glibc", goos: "linux", goarch: "amd64", probes: []string{glibc}, want: "linux/amd64"},
+		{name: "musl", goos: "linux", goarch: "arm64", probes: []string{musl}, want: "linux/arm64/musl"},
+		{name: "static falls back", goos: "linux", goarch: "arm64", probes: []string{static, missing, musl}, want: "linux/arm64/musl"},
+		{name: "first loader wins", goos: "linux", goarch: "amd64", probes: []string{glibc, musl}, want: "linux/amd64"},
+		{name: "undetectable", goos: "linux", goarch: "amd64", probes: []string{static, missing}, want: "linux/amd64"},
+		{name: "not linux", goos: "darwin", goarch: "arm64", probes: []string{musl}, want: "darwin/arm64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detectPlatform(tt.goos, tt.goarch, tt.probes...).String())
+		})
+	}
+}
+
+func TestResolve_LinuxVariants(t *testing.T) {
+	const checksum = "06d372791ae93b5384da5c81b87e9c816ac7756c1d56810dd05329bfc10b5613"
+	m, err := Parse([]byte(`{"versions": {"1.0.0": {
+		"linux/amd64": {"asset": "mcp-scan-1.0.0-linux-x86_64", "sha256": "` + checksum + `"},
+		"linux/arm64": {"asset": "mcp-scan-1.0.0-linux-arm64", "sha256": "` + checksum + `"},
+		"linux/amd64/musl": {"asset": "mcp-scan-1.0.0-linux-musl-x86_64", "sha256": "` + checksum + `"}
+	}}, "options": {"1.0.0": []}}`))
+	require.NoError(t, err)
+
+	asset, err := m.Resolve("1.0.0", Platform{OS: "linux", Arch: "arm64"})
+	require.NoError(t, err)
+	assert.Equal(t, "mcp-scan-1.0.0-linux-arm64", asset.Name)
+
+	asset, err = m.Resolve("1.0.0", Platform{OS: "linux", Arch: "amd64", Libc: LibcMusl})
+	require.NoError(t, err)
+	assert.Equal(t, "mcp-scan-1.0.0-linux-musl-x86_64", asset.Name)
+
+	_, err = m.Resolve("1.0.0", Platform{OS: "linux", Arch: "arm64", Libc: LibcMusl})
+	require.ErrorIs(t, err, ErrUnsupportedPlatform, "glibc builds are not used on musl systems")
+	assert.Contains(t, err.Error(), "linux/amd64, linux/amd64/musl, linux/arm64")
+}
@@ -1,16 +1,23 @@
 package mcpscan
 
 import (
+	"bytes"
+	"context"
+	stderrors "errors"
 	"fmt"
+	"os"
 	"os/exec"
-	"runtime"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/errors"
 	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/helpers"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/manifest"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/policy"
 	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
 	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy/interceptor"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/results"
 	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/runner"
 	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/utils"
 	"github.com/snyk/go-application-framework/pkg/configuration"
@@ -20,32 +27,6 @@ import (
 
 type ScanResolutionHandlerFunc func(ctx workflow.InvocationContext, config configuration.Configuration, logger *zerolog.Logger) ([]workflow.Data, error)
 
-const (
-	tenantIDFlagPrefix = "--tenant-id="
-	clientIDFlagPrefix = "--client-id="
-)
-
-func checksumForCurrentPlatform() (string, error) {
-	switch runtime.GOOS {
-	case "linux":
-		if runtime.GOARCH == "amd64" {
-			if MCPScanBinaryChecksumLinuxAmd64 == "" {
-				return "", fmt.Errorf("checksum not configured for linux/amd64 platform")
-			}
-			return MCPScanBinaryChecksumLinuxAmd64, nil
-		}
-	case "darwin":
-		if runtime.GOARCH == "arm64" {
-			if MCPScanBinaryChecksumMacOSArm64 == "" {
-				return "", fmt.Errorf("checksum not configured for darwin/arm64 platform")
-			}
-			return MCPScanBinaryChecksumMacOSArm64, nil
-		}
-	}
-
-	return "", fmt.Errorf("unsupported platform %s/%s", runtime.GOOS, runtime.GOARCH)
-}
-
 //nolint:gocyclo,nestif // Workflow wiring has necessary branching; extracting further would hurt clarity.
 func Workflow(ctx workflow.InvocationContext, _ []workflow.Data) ([]workflow.Data, error) {
 	config := ctx.GetConfiguration()
@@ -55,23 +36,101 @@ func Workflow(ctx workflow.InvocationContext, _ []workflow.Data) ([]workflow.Dat
 
 	experimental := config.GetBool(FlagExperimental)
 	json := config.GetBool(FlagJSON)
+	sarif := config.GetBool(FlagSarif)
 	noUpload := config.GetBool(FlagNoUpload)
 
-	isHelp := false
 	// As this is an experimental feature, we only want to continue if the experimental flag is set
 	if !experimental {
 		logger.Debug().Msg("Required experimental flag is not present")
 		return nil, errors.NewCommandIsExperimentalError().SnykError
 	}
 
-	checksum, checksumErr := checksumForCurrentPlatform()
-	if checksumErr != nil {
-		logger.Debug().Err(checksumErr).Msg("Unsupported platform or checksum not configured for mcp-scan binary")
-		return nil, checksumErr
+	// Clean up after earlier runs that crashed before removing their temp files
+	runner.SweepStaleTempFiles(logger, os.TempDir(), config.GetString(configuration.TEMP_DIR_PATH))
+
+	scannerManifest, err := manifest.Load()
+	if err != nil {
+		return nil, err
+	}
+	scannerVersion, err := resolveScannerVersion(config, scannerManifest)
+	if err != nil {
+		return nil, err
+	}
+	args, err := parseScanArgs(config.GetStringSlice(configuration.RAW_CMD_ARGS), getFlagSet(), scannerManifest, scannerVersion)
+	if err != nil {
+		if outErr := ui.OutputError(err); outErr != nil {
+			logger.Error().Err(outErr).Msg("Failed to output invalid argument error")
+		}
+		return nil, err
+	}
+
+	// Help is rendered from the embedded manifest, without authentication or the scanner binary
+	if args.Help {
+		return []workflow.Data{
+			workflow.NewData(ScanDataTypeID, contentTypeText, renderHelp(getFlagSet(), scannerManifest, scannerVersion)),
+		}, nil
+	}
+
+	outputFormat, err := resolveOutputFormat(config)
+	if err != nil {
+		if outErr := ui.OutputError(err); outErr != nil {
+			logger.Error().Err(outErr).Msg("Failed to output invalid output format error")
+		}
+		return nil, err
+	}
+
+	severityThreshold, failOn, err := resolveSeverityOptions(config)
+	if err != nil {
+		if outErr := ui.OutputError(err); outErr != nil {
+			logger.Error().Err(outErr).Msg("Failed to output invalid severity option error")
+		}
+		return nil, err
+	}
+
+	ignorePolicy, err := policy.Load(config.GetString(FlagPolicyPath))
+	if err != nil {
+		policyErr := errors.NewInvalidPolicyError(err.Error()).SnykError
+		if outErr := ui.OutputError(policyErr); outErr != nil {
+			logger.Error().Err(outErr).Msg("Failed to output invalid policy error")
+		}
+		return nil, policyErr
+	}
+
+	egressPolicy, err := resolveEgressPolicy(config, ignorePolicy.Egress)
+	if err != nil {
+		if outErr := ui.OutputError(err); outErr != nil {
+			logger.Error().Err(outErr).Msg("Failed to output invalid egress policy error")
+		}
+		return nil, err
+	}
+
+	harFile := config.GetString(FlagHARFile)
+	harBodyLimit, err := resolveHARBodyLimit(config)
+	if err != nil {
+		if outErr := ui.OutputError(err); outErr != nil {
+			logger.Error().Err(outErr).Msg("Failed to output invalid HAR body limit error")
+		}
+		return nil, err
 	}
 
-	// Process raw args
-	rawArgs := config.GetStringSlice(configuration.RAW_CMD_ARGS)
+	var baseline *results.ScanResult
+	if baselinePath := config.GetString(FlagBaseline); baselinePath != "" {
+		baseline, err = results.LoadBaseline(baselinePath)
+		if err != nil {
+			baselineErr := errors.NewInvalidFlagValueError(fmt.Sprintf("Invalid --%s: %s", FlagBaseline, err)).SnykError
+			if outErr := ui.OutputError(baselineErr); outErr != nil {
+				logger.Error().Err(outErr).Msg("Failed to output invalid baseline error")
+			}
+			return nil, baselineErr
+		}
+		baseline.FilterBySeverity(severityThreshold)
+	}
+
+	scannerBinary, err := resolveScannerBinary(config, scannerManifest, manifest.CurrentPlatform())
+	if err != nil {
+		logger.Debug().Err(err).Msg("Unsupported platform or scanner version for mcp-scan binary")
+		return nil, err
+	}
 
 	clientID := config.GetString(FlagClientID)
 	if clientID != "" && !utils.IsValidUUID(clientID) {
@@ -93,36 +152,9 @@ func Workflow(ctx workflow.InvocationContext, _ []workflow.Data) ([]workflow.Dat
 		return nil, err
 	}
 
-	filteredArgs := make([]string, 0, len(rawArgs))
-	for _, a := range rawArgs {
-		if a == "mcp-scan" || a == "--experimental" || a == "--no-upload" {
-			continue
-		}
-		if a == "help" {
-			isHelp = true
-		}
-		if len(a) >= len(tenantIDFlagPrefix) && a[:len(tenantIDFlagPrefix)] == tenantIDFlagPrefix {
-			continue
-		}
-		if len(a) >= len(clientIDFlagPrefix) && a[:len(clientIDFlagPrefix)] == clientIDFlagPrefix {
-			continue
-		}
-
-		filteredArgs = append(filteredArgs, a)
-	}
-	// Run help if requested
-	if isHelp {
-		exitCode, err := runner.ExecuteBinary(ctx, []string{"help"}, MCPScanBinaryVersion, checksum, nil)
-		if err != nil {
-			logger.Debug().Err(err).Int("exitCode", exitCode).Msg("Error running mcp-scan help binary")
-			return nil, fmt.Errorf("failed to run mcp-scan help binary: %w", err)
-		}
-		return nil, nil
-	}
-
 	// When --no-upload is set, we must be logged in but don't need client-id
 	if noUpload {
-		_, err := engine.InvokeWithConfig(localworkflows.WORKFLOWID_WHOAMI, config)
+		_, err = engine.InvokeWithConfig(localworkflows.WORKFLOWID_WHOAMI, config)
 		if err != nil {
 			unauthErr := errors.NewUnauthorizedError("--no-upload requires authentication. Run `snyk auth` to authenticate.").SnykError
 			if outErr := ui.OutputError(unauthErr); outErr != nil {
@@ -138,7 +170,7 @@ func Workflow(ctx workflow.InvocationContext, _ []workflow.Data) ([]workflow.Dat
 		// 3. Error otherwise
 		isLoggedIn := false
 
-		_, err := engine.InvokeWithConfig(localworkflows.WORKFLOWID_WHOAMI, config)
+		_, err = engine.InvokeWithConfig(localworkflows.WORKFLOWID_WHOAMI, config)
 
 		if err == nil {
 			isLoggedIn = true
@@ -149,6 +181,9 @@ func Workflow(ctx workflow.InvocationContext, _ []workflow.Data) ([]workflow.Dat
 				if json {
 					return nil, fmt.Errorf("tenant ID is required when using --json flag. Please provide it using --tenant-id")
 				}
+				if sarif {
+					return nil, fmt.Errorf("tenant ID is required when using --sarif flag. Please provide it using --tenant-id")
+				}
 				tenantID, err = helpers.GetTenantID(ctx, tenantID)
 				if err != nil {
 					return nil, fmt.Errorf("failed to get tenant ID: %w", err)
@@ -184,16 +219,20 @@ func Workflow(ctx workflow.InvocationContext, _ []workflow.Data) ([]workflow.Dat
 		}
 	}
 
-	filteredArgs = append([]string{"scan"}, filteredArgs...)
+	// The scanner always reports JSON so the results can be parsed; rendering is done by the output workflow
+	var extraFlags []string
+	if !args.hasScannerFlag(FlagJSON) {
+		extraFlags = append(extraFlags, "--"+FlagJSON)
+	}
 
 	// Always set analysis URL
 	analysisServerURL := fmt.Sprintf("%s/hidden/mcp-scan/analysis-machine?version=2025-09-02", ctx.GetConfiguration().GetString(configuration.API_URL))
-	filteredArgs = append(filteredArgs, "--analysis-url", analysisServerURL)
+	extraFlags = append(extraFlags, "--analysis-url", analysisServerURL)
 
 	// Only add control server arguments when not using --no-upload
 	if !noUpload {
 		controlServerURL := fmt.Sprintf("%s/hidden/mcp-scan/push?version=2025-08-28", ctx.GetConfiguration().GetString(configuration.API_URL))
-		filteredArgs = append(filteredArgs,
+		extraFlags = append(extraFlags,
 			"--control-server", controlServerURL,
 			"--control-server-H", "x-client-id: "+clientID,
 		)
@@ -203,26 +242,39 @@ func Workflow(ctx workflow.InvocationContext, _ []workflow.Data) ([]workflow.Dat
 			return nil, fmt.Errorf("failed to get uname: %w", err)
 		}
 		controlIdentifier := strings.TrimSpace(string(unameOut))
-		filteredArgs = append(filteredArgs, "--control-identifier", controlIdentifier)
+		extraFlags = append(extraFlags, "--control-identifier", controlIdentifier)
+	}
+
+	scannerArgs := args.scannerArgs(extraFlags...)
+	if config.GetBool(FlagDryRun) {
+		report, err := newDryRunReport(config, scannerBinary, tenantID, clientID, scannerArgs)
+		if err != nil {
+			return nil, err
+		}
+		return newReportOutput(config, ScanDataTypeID, report, renderDryRun(report))
 	}
 
 	// Initialize proxy for credential injection
-	caData, err := proxy.InitCA(config, MCPScanBinaryVersion, logger)
+	caData, err := proxy.InitCA(config, scannerBinary.Version, logger)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to initialize proxy CA")
 		return nil, fmt.Errorf("failed to initialize proxy CA: %w", err)
 	}
 
-	wrapperProxy, err := proxy.NewWrapperProxy(config, MCPScanBinaryVersion, logger, *caData)
+	wrapperProxy, err := proxy.NewWrapperProxy(config, scannerBinary.Version, logger, *caData)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to create wrapper proxy")
 		return nil, fmt.Errorf("failed to create wrapper proxy: %w", err)
 	}
 
-	// Register interceptor to inject credentials via framework's networking layer
+	// Register interceptor to inject credentials into Snyk API requests via the framework's networking layer
 	networkInterceptor := interceptor.NewNetworkInjector(ctx)
 	wrapperProxy.RegisterInterceptor(networkInterceptor)
 	logger.Debug().Msg("Registered network interceptor for credential injection")
+	wrapperProxy.SetEgressPolicy(egressPolicy)
+	if harFile != "" {
+		wrapperProxy.CaptureHAR(harBodyLimit, clientID)
+	}
 
 	err = wrapperProxy.Start()
 	if err != nil {
@@ -234,12 +286,61 @@ func Workflow(ctx workflow.InvocationContext, _ []workflow.Data) ([]workflow.Dat
 	proxyInfo := wrapperProxy.ProxyInfo()
 	logger.Debug().Int("proxyPort", proxyInfo.Port).Msg("Proxy started successfully")
 
-	// Run the embedded binary
-	exitCode, err := runner.ExecuteBinary(ctx, filteredArgs, MCPScanBinaryVersion, checksum, proxyInfo)
-	if err != nil {
-		logger.Debug().Err(err).Int("exitCode", exitCode).Msg("Error running mcp-scan binary")
-		return nil, fmt.Errorf("failed to run mcp-scan binary: %w", err)
+	// Run the embedded binary, capturing its JSON report
+	var scanOutput bytes.Buffer
+	exitCode, runErr := runner.ExecuteBinary(ctx, scannerArgs, scannerBinary, proxyInfo, &scanOutput)
+	// The HAR file is most useful when the scan failed, so it is written before any error is returned
+	if harFile != "" {
+		if harErr := writeHARFile(harFile, wrapperProxy); harErr != nil {
+			logger.Error().Err(harErr).Msg("Failed to write HAR file")
+			if outErr := ui.OutputError(harErr); outErr != nil {
+				logger.Error().Err(outErr).Msg("Failed to output HAR file error")
+			}
+		} else {
+			logger.Debug().Str("path", harFile).Msg("Wrote proxy traffic to HAR file")
+		}
+	}
+	if stderrors.Is(runErr, context.DeadlineExceeded) {
+		logger.Debug().Err(runErr).Dur("timeout", scannerBinary.Timeout).Msg("mcp-scan binary timed out")
+		return nil, errors.NewScannerFailedError(fmt.Sprintf("mcp-scan did not finish within %s. Use --%s to allow more time.", scannerBinary.Timeout, FlagTimeout), runErr).SnykError
+	}
+	if runErr != nil && exitCode < 0 {
+		logger.Debug().Err(runErr).Msg("Error running mcp-scan binary")
+		return nil, fmt.Errorf("failed to run mcp-scan binary: %w", runErr)
+	}
+
+	for _, denied := range egressPolicy.Denied() {
+		logger.Warn().Str("host", denied.Host).Int("attempts", denied.Attempts).Str("mode", string(egressPolicy.Mode())).
+			Msg("Connection outside the egress allowlist")
+	}
+
+	// A non-zero exit code with a readable report means findings; without one the scanner crashed
+	scanResult, parseErr := results.Parse(scanOutput.Bytes())
+	if parseErr != nil {
+		logger.Debug().Str("output", scanOutput.String()).Msg("Unparsable mcp-scan output")
+		if runErr != nil {
+			logger.Debug().Err(runErr).Int("exitCode", exitCode).Msg("mcp-scan binary failed")
+			message := fmt.Sprintf("mcp-scan binary exited with code %d without producing results%s", exitCode, egressHint(egressPolicy))
+			return nil, errors.NewScannerFailedError(message, runErr).SnykError
+		}
+		return nil, errors.NewScannerFailedError("mcp-scan binary produced unreadable results", parseErr).SnykError
+	}
+	if runErr != nil {
+		logger.Debug().Err(runErr).Int("exitCode", exitCode).Msg("mcp-scan binary exited with non-zero code but reported results")
+	}
+
+	if !scanResult.HasScanTargets() {
+		return nil, errors.NewNothingToScanError().SnykError
+	}
+	for _, rule := range ignorePolicy.Apply(scanResult, time.Now()) {
+		logger.Warn().Str("code", rule.Code).Str("server", rule.Server).Str("expires", rule.Expires).Msg("Ignoring expired policy rule")
+	}
+	scanResult.Egress = newEgressReport(egressPolicy)
+	attributeNetwork(scanResult, wrapperProxy.Traffic())
+	scanResult.FilterBySeverity(severityThreshold)
+	if baseline != nil {
+		scanResult.ApplyBaseline(baseline)
 	}
 
-	return []workflow.Data{}, nil
+	return newScanOutput(scanResult, scannerBinary.Version, failOn, outputFormat)
 }
@@ -223,6 +223,8 @@ func TestFlagConfiguration(t *testing.T) {
 		assert.Equal(t, "json", mcpscan.FlagJSON)
 		assert.Equal(t, "skills", mcpscan.FlagSkills)
 		assert.Equal(t, "no-upload", mcpscan.FlagNoUpload)
+		assert.Equal(t, "sarif", mcpscan.FlagSarif)
+		assert.Equal(t, "sarif-file-output", mcpscan.FlagSarifFile)
 	})
 }
 
@@ -0,0 +1,173 @@
+package mcpscan
+
+import (
+	"net"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/results"
+)
+
+// attributeNetwork adds the requests recorded by the proxy to the servers of the scan result. A
+// request belongs to a stdio server if the command line of a process that sent it matches the
+// server's command, and to a remote server if the scanner sent it to the server's URL. All other
+// requests, including those of the scanner to Snyk, are reported for the scan as a whole. So are
+// requests that match several servers equally well, such as those of identically configured
+// servers, rather than guessing which of them sent it.
+func attributeNetwork(result *results.ScanResult, exchanges []proxy.Exchange) {
+	var servers []*results.ServerResult
+	for i := range result.Paths {
+		for j := range result.Paths[i].Servers {
+			servers = append(servers, &result.Paths[i].Servers[j])
+		}
+	}
+
+	activities := map[*results.ServerResult]map[string]*results.NetworkActivity{}
+	for _, exchange := range exchanges {
+		server := matchServer(servers, exchange)
+		if activities[server] == nil {
+			activities[server] = map[string]*results.NetworkActivity{}
+		}
+		activity := activities[server][exchange.Host]
+		if activity == nil {
+			activity = &results.NetworkActivity{Host: exchange.Host}
+			activities[server][exchange.Host] = activity
+		}
+		activity.Requests++
+		if !slices.Contains(activity.Methods, exchange.Method) {
+			activity.Methods = append(activity.Methods, exchange.Method)
+		}
+		if exchange.Blocked {
+			activity.Blocked++
+		}
+		activity.BytesSent += exchange.BytesSent
+		activity.BytesReceived += exchange.BytesReceived
+	}
+
+	for _, server := range servers {
+		server.Network = sortedActivities(activities[server])
+	}
+	result.Network = sortedActivities(activities[nil])
+	result.NetworkUnattributed = !proxy.ProcessAttributionSupported && len(result.Network) > 0
+}
+
+func sortedActivities(byHost map[string]*results.NetworkActivity) []results.NetworkActivity {
+	sorted := make([]results.NetworkActivity, 0, len(byHost))
+	for _, activity := range byHost {
+		sorted = append(sorted, *activity)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Host < sorted[j].Host })
+	if len(sorted) == 0 {
+		return nil
+	}
+	return sorted
+}
+
+// matchServer returns the server that sent the request, nil for the scanner and unknown processes.
+func matchServer(servers []*results.ServerResult, exchange proxy.Exchange) *results.ServerResult {
+	// The chain ends with the scanner, which may run as several processes of the same executable
+	processes := exchange.Processes
+	if len(processes) > 0 {
+		scanner := executable(processes[len(processes)-1])
+		for len(processes) > 0 && executable(processes[len(processes)-1]) == scanner {
+			processes = processes[:len(processes)-1]
+		}
+	}
+
+	var best *results.ServerResult
+	bestScore, tied := 0, false
+	for _, server := range servers {
+		score := commandScore(server, processes)
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = server, score, false
+		case score > 0 && score == bestScore:
+			tied = true
+		}
+	}
+	if tied {
+		return nil
+	}
+	if best != nil || len(processes) > 0 {
+		return best
+	}
+
+	for _, server := range servers {
+		if server.URL == "" || urlHost(server.URL) != exchange.Host {
+			continue
+		}
+		if best != nil {
+			return nil
+		}
+		best = server
+	}
+	return best
+}
+
+func executable(process proxy.Process) string {
+	if len(process.Args) == 0 {
+		return ""
+	}
+	return process.Args[0]
+}
+
+// commandScore counts how many words of the server's command line appear in the command lines
+// of the processes, 0 if none of its arguments do. Flags are ignored as they are not specific to
+// a server, and paths are compared by their base name as well, since runners like npx and uvx
+// install the server elsewhere and may rewrite their process titles.
+func commandScore(server *results.ServerResult, processes []proxy.Process) int {
+	if server.Command == "" || len(processes) == 0 {
+		return 0
+	}
+	words := map[string]bool{}
+	for _, process := range processes {
+		for _, arg := range process.Args {
+			// A rewritten title shows up as a single argument holding all words
+			for _, word := range append(strings.Fields(arg), arg) {
+				words[word] = true
+				words[filepath.Base(word)] = true
+			}
+		}
+	}
+
+	argScore, hasArgs := 0, false
+	for _, arg := range server.Args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		hasArgs = true
+		if words[arg] || words[filepath.Base(arg)] {
+			argScore++
+		}
+	}
+	commandMatches := words[server.Command] || words[filepath.Base(server.Command)]
+	switch {
+	case hasArgs && argScore == 0:
+		return 0
+	case !hasArgs && !commandMatches:
+		return 0
+	case commandMatches:
+		return argScore + 1
+	default:
+		return argScore
+	}
+}
+
+// urlHost returns the host:port a URL connects to.
+func urlHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	if parsed.Port() != "" {
+		return parsed.Host
+	}
+	if parsed.Scheme == "https" {
+		return net.JoinHostPort(parsed.Hostname(), "443")
+	}
+	return net.JoinHostPort(parsed.Hostname(), "80")
+}
@@ -0,0 +1,103 @@
+package mcpscan //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/results"
+)
+
+func TestAttributeNetwork(t *testing.T) {
+	result := &results.ScanResult{Paths: []results.PathResult{{
+		Path: "/home/dev/.cursor/mcp.json",
+		Servers: []results.ServerResult{
+			{Name: "filesystem", Command: "npx", Args: []string{"-y", "@modelcontextprotocol/server-filesystem", "/home/dev"}},
+			{Name: "fetch", Command: "/usr/local/bin/uvx", Args: []string{"mcp-server-fetch"}},
+			{Name: "local", Command: "node", Args: []string{"/opt/mcp/server.js"}},
+			{Name: "remote", Type: "http", URL: "https://mcp.example.com/mcp"},
+		},
+	}}}
+
+	scanner := []proxy.Process{{PID: 10, Args: []string{"/cache/mcp-scan", "scan"}}, {PID: 9, Args: []string{"/cache/mcp-scan", "scan"}}}
+	chain := func(args ...[]string) []proxy.Process {
+		processes := make([]proxy.Process, 0, len(args)+len(scanner))
+		for i, a := range args {
+			processes = append(processes, proxy.Process{PID: 100 + i, Args: a})
+		}
+		return append(processes, scanner...)
+	}
+
+	attributeNetwork(result, []proxy.Exchange{
+		// npm rewrites the title of npx, the server itself runs as a child of it
+		{
+			Processes: chain([]string{"node", "/home/dev/.npm/_npx/1/node_modules/.bin/mcp-server-filesystem"}, []string{"npm exec @modelcontextprotocol/server-filesystem /home/dev"}),
+			Method:    http.MethodGet, Host: "telemetry.example.com:443", BytesSent: 10, BytesReceived: 20,
+		},
+		{
+			Processes: chain([]string{"npm", "exec", "@modelcontextprotocol/server-filesystem", "/home/dev"}),
+			Method:    http.MethodGet, Host: "registry.npmjs.org:443", BytesReceived: 1000,
+		},
+		{
+			Processes: chain([]string{"npm", "exec", "@modelcontextprotocol/server-filesystem", "/home/dev"}),
+			Method:    http.MethodHead, Host: "registry.npmjs.org:443", BytesReceived: 24,
+		},
+		{
+			Processes: chain([]string{"python3", "/home/dev/.cache/uv/bin/mcp-server-fetch"}, []string{"uvx", "mcp-server-fetch"}),
+			Method:    http.MethodPost, Host: "collector.example.com:443", BytesSent: 5, Blocked: true,
+		},
+		// node alone does not identify the local server
+		{Processes: chain([]string{"node", "/opt/other.js"}), Method: http.MethodGet, Host: "other.example.com:443"},
+		{Processes: scanner, Method: http.MethodPost, Host: "mcp.example.com:443", BytesSent: 100, BytesReceived: 200},
+		{Processes: scanner, Method: http.MethodPost, Host: "api.snyk.io:443", BytesSent: 300},
+	})
+
+	servers := result.Paths[0].Servers
+	assert.Equal(t, []results.NetworkActivity{
+		{Host: "registry.npmjs.org:443", Methods: []string{"GET", "HEAD"}, Requests: 2, BytesReceived: 1024},
+		{Host: "telemetry.example.com:443", Methods: []string{"GET"}, Requests: 1, BytesSent: 10, BytesReceived: 20},
+	}, servers[0].Network)
+	assert.Equal(t, []results.NetworkActivity{
+		{Host: "collector.example.com:443", Methods: []string{"POST"}, Requests: 1, Blocked: 1, BytesSent: 5},
+	}, servers[1].Network)
+	assert.Nil(t, servers[2].Network)
+	assert.Equal(t, []results.NetworkActivity{
+		{Host: "mcp.example.com:443", Methods: []string{"POST"}, Requests: 1, BytesSent: 100, BytesReceived: 200},
+	}, servers[3].Network)
+	assert.Equal(t, []results.NetworkActivity{
+		{Host: "api.snyk.io:443", Methods: []string{"POST"}, Requests: 1, BytesSent: 300},
+		{Host: "other.example.com:443", Methods: []string{"GET"}, Requests: 1},
+	}, result.Network)
+}
+
+func TestAttributeNetwork_Ambiguous(t *testing.T) {
+	result := &results.ScanResult{Paths: []results.PathResult{
+		{Path: "/home/dev/.cursor/mcp.json", Servers: []results.ServerResult{
+			{Name: "fetch", Command: "uvx", Args: []string{"mcp-server-fetch"}},
+			{Name: "remote", Type: "http", URL: "https://mcp.example.com/mcp"},
+		}},
+		{Path: "/home/dev/.vscode/mcp.json", Servers: []results.ServerResult{
+			{Name: "fetch", Command: "uvx", Args: []string{"mcp-server-fetch"}},
+			{Name: "remote", Type: "http", URL: "https://mcp.example.com/other"},
+		}},
+	}}
+	scanner := proxy.Process{PID: 10, Args: []string{"/cache/mcp-scan", "scan"}}
+
+	attributeNetwork(result, []proxy.Exchange{
+		{Processes: []proxy.Process{{PID: 100, Args: []string{"uvx", "mcp-server-fetch"}}, scanner}, Method: http.MethodGet, Host: "pypi.org:443"},
+		{Processes: []proxy.Process{scanner}, Method: http.MethodPost, Host: "mcp.example.com:443"},
+	})
+
+	for _, path := range result.Paths {
+		for _, server := range path.Servers {
+			assert.Nil(t, server.Network, "requests matching several servers are not attributed to either")
+		}
+	}
+	assert.Equal(t, []results.NetworkActivity{
+		{Host: "mcp.example.com:443", Methods: []string{"POST"}, Requests: 1},
+		{Host: "pypi.org:443", Methods: []string{"GET"}, Requests: 1},
+	}, result.Network)
+	assert.Equal(t, !proxy.ProcessAttributionSupported, result.NetworkUnattributed)
+}
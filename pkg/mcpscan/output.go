@@ -0,0 +1,108 @@
+package mcpscan
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/local_workflows/content_type"
+	"github.com/snyk/go-application-framework/pkg/local_workflows/json_schemas"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/errors"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/results"
+)
+
+const (
+	contentTypeJSON  = "application/json"
+	contentTypeText  = "text/plain"
+	contentTypeSarif = "application/sarif+json"
+)
+
+// resolveOutputFormat returns the content type of the scan results: SARIF for --sarif and
+// --sarif-file-output, JSON for --json and human readable text otherwise. The output workflow
+// writes the first data item to stdout and to every output file alike, whatever its content type,
+// so only one representation can be returned and JSON and SARIF cannot be combined.
+func resolveOutputFormat(config configuration.Configuration) (string, error) {
+	sarif := config.GetBool(FlagSarif) || config.GetString(FlagSarifFile) != ""
+	switch {
+	case sarif && config.GetBool(FlagJSON):
+		return "", errors.NewInvalidFlagValueError(fmt.Sprintf("--%s cannot be combined with --%s or --%s", FlagJSON, FlagSarif, FlagSarifFile)).SnykError
+	case sarif:
+		return contentTypeSarif, nil
+	case config.GetBool(FlagJSON):
+		return contentTypeJSON, nil
+	default:
+		return contentTypeText, nil
+	}
+}
+
+// newScanOutput converts parsed scan results into workflow data in the given format, followed by
+// the test summary the CLI derives its exit code from.
+func newScanOutput(result *results.ScanResult, scannerVersion string, failOn results.Severity, format string) ([]workflow.Data, error) {
+	var output workflow.Data
+	switch format {
+	case contentTypeSarif:
+		payload, err := json.Marshal(results.ToSarif(result, scannerVersion))
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize SARIF results: %w", err)
+		}
+		output = workflow.NewData(ScanDataTypeID, contentTypeSarif, payload)
+	case contentTypeJSON:
+		payload, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize scan results: %w", err)
+		}
+		output = workflow.NewData(ScanDataTypeID, contentTypeJSON, payload)
+	default:
+		output = workflow.NewData(ScanDataTypeID, contentTypeText, results.RenderHuman(result))
+	}
+
+	summaryPayload, err := json.Marshal(newTestSummary(result, failOn))
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize test summary: %w", err)
+	}
+
+	return []workflow.Data{
+		output,
+		workflow.NewData(ScanDataTypeID, content_type.TEST_SUMMARY, summaryPayload),
+	}, nil
+}
+
+// newReportOutput returns a report as JSON for --json and as text otherwise.
+func newReportOutput(config configuration.Configuration, id workflow.Identifier, report any, text string) ([]workflow.Data, error) {
+	if !config.GetBool(FlagJSON) {
+		return []workflow.Data{workflow.NewData(id, contentTypeText, text)}, nil
+	}
+	payload, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize report: %w", err)
+	}
+	return []workflow.Data{workflow.NewData(id, contentTypeJSON, payload)}, nil
+}
+
+// newTestSummary builds the summary the CLI uses to derive its exit code. Only severities at or
+// above failOn are included, so open issues in the summary mean the command should fail.
+func newTestSummary(result *results.ScanResult, failOn results.Severity) *json_schemas.TestSummary {
+	summary := json_schemas.NewTestSummary(ScanWorkflowIDStr, "")
+	summary.Artifacts = result.Summary.Servers
+	for _, s := range results.Severities {
+		if s.Rank() < failOn.Rank() {
+			continue
+		}
+		open := result.Summary.BySeverity[s]
+		ignored := 0
+		for _, suppressed := range result.Suppressed {
+			if suppressed.Severity == s {
+				ignored++
+			}
+		}
+		summary.Results = append(summary.Results, json_schemas.TestSummaryResult{
+			Severity: string(s),
+			Total:    open + ignored,
+			Open:     open,
+			Ignored:  ignored,
+		})
+	}
+	return summary
+}
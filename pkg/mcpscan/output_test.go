@@ -0,0 +1,136 @@
+package mcpscan //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/snyk/error-catalog-golang-public/snyk_errors"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	localworkflows "github.com/snyk/go-application-framework/pkg/local_workflows"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/results"
+)
+
+// runOutputWorkflow passes data through the output workflow of the CLI and returns what it wrote to stdout.
+func runOutputWorkflow(t *testing.T, config configuration.Configuration, data []workflow.Data) string {
+	t.Helper()
+	engine := workflow.NewWorkFlowEngine(config)
+	logger := zerolog.Nop()
+	engine.SetLogger(&logger)
+	require.NoError(t, localworkflows.InitOutputWorkflow(engine))
+	require.NoError(t, engine.Init())
+
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+	captured := make(chan []byte)
+	go func() {
+		output, _ := io.ReadAll(reader)
+		captured <- output
+	}()
+
+	_, err = engine.InvokeWithInput(localworkflows.WORKFLOWID_OUTPUT_WORKFLOW, data)
+	require.NoError(t, writer.Close())
+	output := <-captured
+	require.NoError(t, err)
+	return string(output)
+}
+
+func newOutputTestResult() *results.ScanResult {
+	result := &results.ScanResult{
+		Paths:  []results.PathResult{{Path: "/home/user/.cursor/mcp.json", Servers: []results.ServerResult{{Name: "fs"}}}},
+		Issues: []results.Issue{{Code: "E001", Message: "Prompt injection in tool description", Severity: results.SeverityHigh, ConfigPath: "/home/user/.cursor/mcp.json"}},
+	}
+	result.UpdateSummary()
+	return result
+}
+
+func TestScanOutput_OutputWorkflow(t *testing.T) {
+	tests := []struct {
+		name   string
+		flags  map[string]any
+		assert func(t *testing.T, stdout string)
+	}{
+		{
+			name:  "text by default",
+			flags: map[string]any{},
+			assert: func(t *testing.T, stdout string) {
+				t.Helper()
+				assert.Equal(t, results.RenderHuman(newOutputTestResult()), stdout[:len(stdout)-1])
+			},
+		},
+		{
+			name:  "JSON with --json",
+			flags: map[string]any{FlagJSON: true},
+			assert: func(t *testing.T, stdout string) {
+				t.Helper()
+				var result results.ScanResult
+				require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+				assert.Len(t, result.Issues, 1)
+			},
+		},
+		{
+			name:  "SARIF with --sarif",
+			flags: map[string]any{FlagSarif: true},
+			assert: func(t *testing.T, stdout string) {
+				t.Helper()
+				assert.Contains(t, stdout, `"version":"2.1.0"`)
+				assert.Contains(t, stdout, `"ruleId":"E001"`)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := configuration.NewWithOpts()
+			for key, value := range tt.flags {
+				config.Set(key, value)
+			}
+			format, err := resolveOutputFormat(config)
+			require.NoError(t, err)
+			data, err := newScanOutput(newOutputTestResult(), MCPScanBinaryVersion, results.SeverityLow, format)
+			require.NoError(t, err)
+
+			tt.assert(t, runOutputWorkflow(t, config, data))
+		})
+	}
+}
+
+func TestScanOutput_OutputWorkflowSarifFile(t *testing.T) {
+	sarifFile := filepath.Join(t.TempDir(), "results.sarif")
+	config := configuration.NewWithOpts()
+	config.Set(FlagSarifFile, sarifFile)
+	format, err := resolveOutputFormat(config)
+	require.NoError(t, err)
+	data, err := newScanOutput(newOutputTestResult(), MCPScanBinaryVersion, results.SeverityLow, format)
+	require.NoError(t, err)
+
+	runOutputWorkflow(t, config, data)
+
+	written, err := os.ReadFile(sarifFile)
+	require.NoError(t, err)
+	var log results.SarifLog
+	require.NoError(t, json.Unmarshal(written, &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	require.Len(t, log.Runs[0].Results, 1)
+	assert.Equal(t, "file:///home/user/.cursor/mcp.json", log.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+}
+
+func TestResolveOutputFormat_JSONAndSarif(t *testing.T) {
+	config := configuration.NewWithOpts()
+	config.Set(FlagJSON, true)
+	config.Set(FlagSarifFile, filepath.Join(t.TempDir(), "results.sarif"))
+	_, err := resolveOutputFormat(config)
+	var snykErr snyk_errors.Error
+	require.ErrorAs(t, err, &snykErr)
+	assert.Contains(t, snykErr.Detail, "--json cannot be combined")
+}
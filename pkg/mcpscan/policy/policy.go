@@ -0,0 +1,172 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/results"
+)
+
+// DefaultFileName is the policy file picked up from the working directory when no path is given.
+const DefaultFileName = ".snyk-mcp.yaml"
+
+const expiresLayout = "2006-01-02"
+
+// Policy lists the findings that have been accepted and should not be reported, and the network
+// destinations a scan may reach.
+type Policy struct {
+	Version string `yaml:"version"`
+	Ignore  []Rule `yaml:"ignore"`
+	Egress  Egress `yaml:"egress"`
+}
+
+// Egress restricts the hosts the scanner and the MCP servers it starts may connect to, in addition
+// to the Snyk API. Mode is off, warn or block; it defaults to block when hosts are allowed and to
+// warn otherwise.
+type Egress struct {
+	Mode  string   `yaml:"mode"`
+	Allow []string `yaml:"allow"`
+}
+
+// Rule suppresses findings matching all of its non-empty selectors until it expires.
+// Path is matched as a glob against the MCP client config path reported by the scanner.
+type Rule struct {
+	Code    string `yaml:"code"`
+	Server  string `yaml:"server"`
+	Tool    string `yaml:"tool"`
+	Path    string `yaml:"path"`
+	Reason  string `yaml:"reason"`
+	Expires string `yaml:"expires"`
+
+	expiresAt time.Time
+}
+
+// Load reads the policy at path. If path is empty the default policy file is used when present;
+// a missing default file yields an empty policy.
+func Load(path string) (*Policy, error) {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultFileName
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return &Policy{}, nil
+		}
+		return nil, fmt.Errorf("failed to read policy file %s: %w", path, err)
+	}
+
+	return Parse(data)
+}
+
+// Parse decodes and validates a policy document. Every rule needs at least one selector,
+// a reason and an expiry date.
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+
+	for i := range p.Ignore {
+		rule := &p.Ignore[i]
+		if rule.Code == "" && rule.Server == "" && rule.Tool == "" && rule.Path == "" {
+			return nil, fmt.Errorf("ignore rule %d must set at least one of code, server, tool or path", i+1)
+		}
+		if rule.Reason == "" {
+			return nil, fmt.Errorf("ignore rule %d is missing a reason", i+1)
+		}
+		if rule.Expires == "" {
+			return nil, fmt.Errorf("ignore rule %d is missing an expiry date", i+1)
+		}
+		expiresAt, err := time.Parse(expiresLayout, rule.Expires)
+		if err != nil {
+			return nil, fmt.Errorf("ignore rule %d has an invalid expiry date %q, expected YYYY-MM-DD", i+1, rule.Expires)
+		}
+		if _, err := filepath.Match(rule.Path, ""); err != nil {
+			return nil, fmt.Errorf("ignore rule %d has an invalid path pattern %q: %w", i+1, rule.Path, err)
+		}
+		rule.expiresAt = expiresAt
+	}
+
+	switch p.Egress.Mode {
+	case "", "off", "warn", "block":
+	default:
+		return nil, fmt.Errorf("egress mode %q is invalid, expected off, warn or block", p.Egress.Mode)
+	}
+	for i, host := range p.Egress.Allow {
+		if host == "" {
+			return nil, fmt.Errorf("egress allow entry %d is empty", i+1)
+		}
+	}
+
+	return &p, nil
+}
+
+// Expired reports whether the rule no longer applies at the given time.
+// A rule stays valid for the whole day it expires on.
+func (r *Rule) Expired(now time.Time) bool {
+	return !now.Before(r.expiresAt.AddDate(0, 0, 1))
+}
+
+// Matches reports whether the issue is selected by the rule.
+func (r *Rule) Matches(issue results.Issue) bool {
+	if r.Code != "" && r.Code != issue.Code {
+		return false
+	}
+	if r.Server != "" && r.Server != issue.ServerName {
+		return false
+	}
+	if r.Tool != "" && r.Tool != issue.ToolName {
+		return false
+	}
+	if r.Path != "" && r.Path != issue.ConfigPath {
+		if ok, _ := filepath.Match(r.Path, issue.ConfigPath); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply moves issues matched by an unexpired rule from the result's issues to its suppressed issues.
+// It returns the rules that were skipped because they expired.
+func (p *Policy) Apply(result *results.ScanResult, now time.Time) []Rule {
+	var expired []Rule
+	active := make([]*Rule, 0, len(p.Ignore))
+	for i := range p.Ignore {
+		if p.Ignore[i].Expired(now) {
+			expired = append(expired, p.Ignore[i])
+			continue
+		}
+		active = append(active, &p.Ignore[i])
+	}
+
+	remaining := make([]results.Issue, 0, len(result.Issues))
+	for _, issue := range result.Issues {
+		var matched *Rule
+		for _, rule := range active {
+			if rule.Matches(issue) {
+				matched = rule
+				break
+			}
+		}
+		if matched == nil {
+			remaining = append(remaining, issue)
+			continue
+		}
+		result.Suppressed = append(result.Suppressed, results.SuppressedIssue{
+			Issue:   issue,
+			Reason:  matched.Reason,
+			Expires: matched.Expires,
+		})
+	}
+	result.Issues = remaining
+	result.UpdateSummary()
+
+	return expired
+}
@@ -0,0 +1,110 @@
+package policy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/policy"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/results"
+)
+
+const testPolicy = `version: v1
+ignore:
+  - server: internal-fs
+    code: W001
+    reason: Internal filesystem server is expected to have broad access
+    expires: 2026-12-31
+  - path: /home/*/.cursor/mcp.json
+    tool: run_query
+    reason: Accepted by security review
+    expires: 2026-01-31
+`
+
+func newResult() *results.ScanResult {
+	r := &results.ScanResult{
+		Issues: []results.Issue{
+			{Code: "W001", Severity: results.SeverityMedium, ConfigPath: "/home/dev/.vscode/mcp.json", ServerName: "internal-fs"},
+			{Code: "E001", Severity: results.SeverityHigh, ConfigPath: "/home/dev/.vscode/mcp.json", ServerName: "internal-fs"},
+			{Code: "E002", Severity: results.SeverityHigh, ConfigPath: "/home/dev/.cursor/mcp.json", ServerName: "db", ToolName: "run_query"},
+		},
+	}
+	r.UpdateSummary()
+	return r
+}
+
+func TestApply(t *testing.T) {
+	p, err := policy.Parse([]byte(testPolicy))
+	require.NoError(t, err)
+
+	result := newResult()
+	expired := p.Apply(result, time.Date(2026, 1, 31, 23, 0, 0, 0, time.UTC))
+	assert.Empty(t, expired)
+
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, "E001", result.Issues[0].Code)
+	require.Len(t, result.Suppressed, 2)
+	assert.Equal(t, "W001", result.Suppressed[0].Code)
+	assert.Equal(t, "Internal filesystem server is expected to have broad access", result.Suppressed[0].Reason)
+	assert.Equal(t, "E002", result.Suppressed[1].Code)
+	assert.Equal(t, 2, result.Summary.Suppressed)
+	assert.Equal(t, 1, result.Summary.Issues)
+}
+
+func TestApply_ExpiredRule(t *testing.T) {
+	p, err := policy.Parse([]byte(testPolicy))
+	require.NoError(t, err)
+
+	result := newResult()
+	expired := p.Apply(result, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+	require.Len(t, expired, 1)
+	assert.Equal(t, "run_query", expired[0].Tool)
+
+	assert.Len(t, result.Issues, 2)
+	assert.Len(t, result.Suppressed, 1)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"missing reason":   "ignore:\n  - code: E001\n    expires: 2026-01-01\n",
+		"missing expiry":   "ignore:\n  - code: E001\n    reason: accepted\n",
+		"invalid expiry":   "ignore:\n  - code: E001\n    reason: accepted\n    expires: next year\n",
+		"missing selector": "ignore:\n  - reason: accepted\n    expires: 2026-01-01\n",
+		"invalid yaml":     "ignore: [",
+		"invalid egress":   "egress:\n  mode: deny\n",
+		"empty allow host": "egress:\n  allow:\n    - \"\"\n",
+	}
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := policy.Parse([]byte(doc))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	p, err := policy.Load("")
+	require.NoError(t, err, "a missing default policy file is not an error")
+	assert.Empty(t, p.Ignore)
+
+	_, err = policy.Load("does-not-exist.yaml")
+	assert.Error(t, err, "a missing explicit policy file is an error")
+
+	require.NoError(t, os.WriteFile(filepath.Join(".", policy.DefaultFileName), []byte(testPolicy), 0o600))
+	p, err = policy.Load("")
+	require.NoError(t, err)
+	assert.Len(t, p.Ignore, 2)
+}
+
+func TestParse_Egress(t *testing.T) {
+	p, err := policy.Parse([]byte("version: v1\negress:\n  mode: warn\n  allow:\n    - registry.npmjs.org\n    - \"*.githubusercontent.com\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, policy.Egress{Mode: "warn", Allow: []string{"registry.npmjs.org", "*.githubusercontent.com"}}, p.Egress)
+	assert.Empty(t, p.Ignore)
+}
@@ -0,0 +1,171 @@
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/elazarl/goproxy"
+)
+
+// EgressMode decides what happens to requests for destinations outside the egress allowlist.
+type EgressMode string
+
+const (
+	// EgressOff lets every request through without recording it.
+	EgressOff EgressMode = "off"
+	// EgressWarn lets requests outside the allowlist through and records them.
+	EgressWarn EgressMode = "warn"
+	// EgressBlock rejects requests outside the allowlist and records them.
+	EgressBlock EgressMode = "block"
+)
+
+// ParseEgressMode validates an egress mode given by the user.
+func ParseEgressMode(value string) (EgressMode, error) {
+	switch mode := EgressMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case EgressOff, EgressWarn, EgressBlock:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown egress mode %q, expected off, warn or block", value)
+	}
+}
+
+// DeniedDestination is a host:port outside the allowlist that the scanner or an MCP server tried to reach.
+type DeniedDestination struct {
+	Host     string
+	Attempts int
+}
+
+// EgressPolicy restricts the destinations reachable through the proxy to an allowlist of hosts.
+// Denied destinations are recorded so they can be reported at the end of the run. A nil policy
+// allows everything.
+type EgressPolicy struct {
+	mode    EgressMode
+	allowed []hostPattern
+
+	mu     sync.Mutex
+	denied map[string]int
+}
+
+// hostPattern matches a host name, or all its subdomains if wildcard is set, on the given port or
+// on any port if port is empty.
+type hostPattern struct {
+	host     string
+	wildcard bool
+	port     string
+}
+
+// NewEgressPolicy creates a policy allowing the given hosts. Hosts are given as a host name with
+// an optional port, as *.example.com for all subdomains of example.com, or as a URL whose host is
+// allowed.
+func NewEgressPolicy(mode EgressMode, allowHosts []string) (*EgressPolicy, error) {
+	if _, err := ParseEgressMode(string(mode)); err != nil {
+		return nil, err
+	}
+	policy := &EgressPolicy{mode: mode, denied: map[string]int{}}
+	for _, host := range allowHosts {
+		pattern, err := parseHostPattern(host)
+		if err != nil {
+			return nil, err
+		}
+		policy.allowed = append(policy.allowed, pattern)
+	}
+	return policy, nil
+}
+
+func parseHostPattern(value string) (hostPattern, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "://") {
+		parsed, err := url.Parse(value)
+		if err != nil || parsed.Hostname() == "" {
+			return hostPattern{}, fmt.Errorf("invalid allowed host %q", value)
+		}
+		return hostPattern{host: strings.ToLower(parsed.Hostname()), port: parsed.Port()}, nil
+	}
+
+	var pattern hostPattern
+	pattern.host = value
+	if host, port, err := net.SplitHostPort(value); err == nil {
+		pattern.host, pattern.port = host, port
+	}
+	pattern.host = strings.ToLower(strings.Trim(pattern.host, "[]"))
+	if rest, ok := strings.CutPrefix(pattern.host, "*."); ok {
+		pattern.host, pattern.wildcard = rest, true
+	}
+	if pattern.host == "" || strings.ContainsAny(pattern.host, "*/ ") {
+		return hostPattern{}, fmt.Errorf("invalid allowed host %q, expected a host name like example.com, *.example.com or example.com:8443", value)
+	}
+	return pattern, nil
+}
+
+func (h hostPattern) matches(host, port string) bool {
+	if h.port != "" && h.port != port {
+		return false
+	}
+	if h.wildcard {
+		return strings.HasSuffix(host, "."+h.host)
+	}
+	return host == h.host
+}
+
+// Mode returns the mode of the policy, EgressOff for a nil policy.
+func (e *EgressPolicy) Mode() EgressMode {
+	if e == nil {
+		return EgressOff
+	}
+	return e.mode
+}
+
+// permit reports whether a request for hostport may pass and records it if it is outside the
+// allowlist. defaultPort is used when hostport has no port.
+func (e *EgressPolicy) permit(hostport, defaultPort string) bool {
+	if e == nil || e.mode == EgressOff {
+		return true
+	}
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host, port = hostport, defaultPort
+	}
+	host = strings.ToLower(strings.TrimSuffix(strings.Trim(host, "[]"), "."))
+	for _, pattern := range e.allowed {
+		if pattern.matches(host, port) {
+			return true
+		}
+	}
+
+	e.mu.Lock()
+	e.denied[net.JoinHostPort(host, port)]++
+	e.mu.Unlock()
+	return e.mode != EgressBlock
+}
+
+// Denied returns the destinations outside the allowlist requested so far, sorted by host.
+func (e *EgressPolicy) Denied() []DeniedDestination {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	denied := make([]DeniedDestination, 0, len(e.denied))
+	for host, attempts := range e.denied {
+		denied = append(denied, DeniedDestination{Host: host, Attempts: attempts})
+	}
+	sort.Slice(denied, func(i, j int) bool { return denied[i].Host < denied[j].Host })
+	return denied
+}
+
+// handleEgress rejects plain HTTP requests outside the allowlist. HTTPS requests have already been
+// checked when their tunnel was opened.
+func (p *WrapperProxy) handleEgress(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+	if req.URL.Scheme == "https" || p.egress.permit(req.URL.Host, "80") {
+		return req, nil
+	}
+	markBlocked(ctx)
+	p.DebugLogger.Printf("Blocked request to %s by the egress policy", req.URL.Host)
+	return req, goproxy.NewResponse(req, goproxy.ContentTypeText, http.StatusForbidden,
+		fmt.Sprintf("%s is not allowed by the snyk mcp-scan egress policy\n", req.URL.Hostname()))
+}
@@ -0,0 +1,119 @@
+package proxy_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
+)
+
+func TestParseEgressMode(t *testing.T) {
+	mode, err := proxy.ParseEgressMode(" Block ")
+	require.NoError(t, err)
+	assert.Equal(t, proxy.EgressBlock, mode)
+
+	_, err = proxy.ParseEgressMode("deny")
+	assert.Error(t, err)
+}
+
+func TestNewEgressPolicy_InvalidHost(t *testing.T) {
+	for _, host := range []string{"", "*", "example.*.com", "example.com/path", "https://"} {
+		_, err := proxy.NewEgressPolicy(proxy.EgressBlock, []string{host})
+		assert.Error(t, err, host)
+	}
+}
+
+func Test_egressPolicy(t *testing.T) {
+	basecache := "testcache"
+	version := "1.1.1"
+
+	config := setup(t, basecache, version)
+	defer teardown(t, basecache)
+
+	newServer := func() *url.URL {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = io.WriteString(w, "ok")
+		}))
+		t.Cleanup(server.Close)
+		serverURL, err := url.Parse(server.URL)
+		require.NoError(t, err)
+		return serverURL
+	}
+	allowedServer := newServer()
+	otherServer := newServer()
+
+	tests := []struct {
+		mode          proxy.EgressMode
+		otherStatus   int
+		connectFailed bool
+		denied        []proxy.DeniedDestination
+	}{
+		{
+			mode:          proxy.EgressBlock,
+			otherStatus:   http.StatusForbidden,
+			connectFailed: true,
+			denied: []proxy.DeniedDestination{
+				{Host: otherServer.Host, Attempts: 2},
+				{Host: "telemetry.example.test:443", Attempts: 1},
+			},
+		},
+		{
+			mode:        proxy.EgressWarn,
+			otherStatus: http.StatusOK,
+			denied: []proxy.DeniedDestination{
+				{Host: otherServer.Host, Attempts: 2},
+				{Host: "telemetry.example.test:443", Attempts: 1},
+			},
+		},
+		{
+			mode:        proxy.EgressOff,
+			otherStatus: http.StatusOK,
+			denied:      []proxy.DeniedDestination{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			egress, err := proxy.NewEgressPolicy(tt.mode, []string{"http://" + allowedServer.Host, "*.snyk.io"})
+			require.NoError(t, err)
+
+			wp, err := proxy.NewWrapperProxy(config, version, &debugLogger, caData)
+			require.NoError(t, err)
+			wp.SetUpstreamProxy(func(*http.Request) (*url.URL, error) { return nil, nil })
+			wp.SetEgressPolicy(egress)
+			require.NoError(t, wp.Start())
+			defer wp.Close()
+
+			proxiedClient, err := helper_getHttpClient(wp, true)
+			require.NoError(t, err)
+
+			res, err := proxiedClient.Get(allowedServer.String())
+			require.NoError(t, err)
+			res.Body.Close()
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+
+			for range 2 {
+				res, err = proxiedClient.Get(otherServer.String())
+				require.NoError(t, err)
+				res.Body.Close()
+				assert.Equal(t, tt.otherStatus, res.StatusCode)
+			}
+
+			// The tunnel is rejected before the proxy resolves the host, so no network access is needed
+			res, err = proxiedClient.Get("https://telemetry.example.test/v1/events")
+			if err == nil {
+				res.Body.Close()
+			}
+			if tt.connectFailed {
+				assert.Error(t, err)
+			}
+
+			assert.Equal(t, tt.denied, egress.Denied())
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultHARBodyLimit is the number of bytes captured of each request and response body unless
+// configured otherwise.
+const DefaultHARBodyLimit = 64 * 1024
+
+const harRedacted = "***"
+
+// harRedactedHeaders carry credentials and are never written to a HAR file.
+var harRedactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Client-Id":         true,
+	"X-Api-Key":           true,
+	"Api-Key":             true,
+	"X-Auth-Token":        true,
+	"Private-Token":       true,
+}
+
+// harRedactedQueryParams carry credentials in URLs and are never written to a HAR file. Names are
+// matched case-insensitively.
+var harRedactedQueryParams = map[string]bool{
+	"api_key":       true,
+	"apikey":        true,
+	"api-key":       true,
+	"key":           true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"auth":          true,
+	"code":          true,
+	"password":      true,
+	"secret":        true,
+	"client_secret": true,
+	"signature":     true,
+	"sig":           true,
+}
+
+// harCapture holds what is needed for a HAR entry beyond the traffic summary of an exchange.
+type harCapture struct {
+	startedAt      time.Time
+	url            string
+	proto          string
+	requestHeader  http.Header
+	requestBody    *bodyCapture
+	status         string
+	responseProto  string
+	responseHeader http.Header
+	respondedAt    time.Time
+	responseBody   *bodyCapture
+	finishedAt     time.Time
+}
+
+// bodyCapture keeps the first bytes of a body up to a limit.
+type bodyCapture struct {
+	mu        sync.Mutex
+	limit     int64
+	data      bytes.Buffer
+	truncated bool
+}
+
+func (c *bodyCapture) write(p []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	remaining := c.limit - int64(c.data.Len())
+	if int64(len(p)) > remaining {
+		p = p[:max(remaining, 0)]
+		c.truncated = true
+	}
+	c.data.Write(p)
+}
+
+func (c *bodyCapture) snapshot() ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return bytes.Clone(c.data.Bytes()), c.truncated
+}
+
+// CaptureHAR makes the proxy keep the headers and the first bodyLimit bytes of the bodies of all
+// requests and responses, so they can be written with WriteHAR. The proxy password and the given
+// secrets are redacted wherever they appear. It must be called before Start.
+func (p *WrapperProxy) CaptureHAR(bodyLimit int64, secrets ...string) {
+	p.traffic.harBodyLimit = max(bodyLimit, 0)
+	p.traffic.captureHAR = true
+	for _, secret := range append([]string{p.proxyPassword}, secrets...) {
+		if secret != "" {
+			p.traffic.harSecrets = append(p.traffic.harSecrets, secret)
+		}
+	}
+}
+
+// WriteHAR writes the requests captured since CaptureHAR as a HAR 1.2 log.
+func (p *WrapperProxy) WriteHAR(w io.Writer) error {
+	log := harLog{Log: harLogBody{
+		Version: "1.2",
+		Creator: harCreator{Name: "snyk mcp-scan", Version: p.cliVersion},
+		Entries: p.traffic.harEntries(),
+	}}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return fmt.Errorf("failed to write HAR: %w", err)
+	}
+	return nil
+}
+
+func (t *trafficRecorder) harEntries() []harEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entries := make([]harEntry, 0, len(t.exchanges))
+	for _, e := range t.exchanges {
+		if e.har != nil {
+			entries = append(entries, t.harEntry(e))
+		}
+	}
+	return entries
+}
+
+// harEntry converts an exchange; the caller holds t.mu.
+func (t *trafficRecorder) harEntry(e *exchange) harEntry {
+	c := e.har
+	entry := harEntry{
+		StartedDateTime: c.startedAt.Format(time.RFC3339Nano),
+		Request: harRequest{
+			Method:      e.method,
+			URL:         t.harURL(c.url),
+			HTTPVersion: c.proto,
+			Cookies:     []harCookie{},
+			Headers:     t.harHeaders(c.requestHeader),
+			QueryString: []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    e.bytesSent.Load(),
+		},
+		Response: harResponse{
+			Status:      e.statusCode,
+			StatusText:  strings.TrimSpace(strings.TrimPrefix(c.status, fmt.Sprint(e.statusCode))),
+			HTTPVersion: c.responseProto,
+			Cookies:     []harCookie{},
+			Headers:     t.harHeaders(c.responseHeader),
+			Content:     harContent{Size: e.bytesReceived.Load(), MimeType: c.responseHeader.Get("Content-Type")},
+			HeadersSize: -1,
+			BodySize:    e.bytesReceived.Load(),
+		},
+		Cache: struct{}{},
+	}
+
+	if parsed, err := url.Parse(c.url); err == nil {
+		for name, values := range parsed.Query() {
+			for _, value := range values {
+				if harRedactedQueryParams[strings.ToLower(name)] {
+					value = harRedacted
+				}
+				entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: t.redact(name), Value: t.redact(value)})
+			}
+		}
+	}
+
+	if body, truncated := c.requestBody.snapshot(); len(body) > 0 {
+		text, encoding := t.harText(body, truncated)
+		entry.Request.PostData = &harPostData{MimeType: c.requestHeader.Get("Content-Type"), Text: text, Comment: harBodyComment(encoding, truncated, len(body))}
+	}
+	if body, truncated := c.responseBody.snapshot(); len(body) > 0 {
+		entry.Response.Content.Text, entry.Response.Content.Encoding = t.harText(body, truncated)
+		entry.Response.Content.Comment = harBodyComment("", truncated, len(body))
+	}
+
+	switch {
+	case e.blocked.Load():
+		entry.Response.StatusText = "Blocked by the egress policy"
+		entry.Comment = "blocked by the egress policy"
+	case e.statusCode == 0:
+		entry.Comment = "no response"
+	}
+	if entry.Response.HTTPVersion == "" {
+		entry.Response.HTTPVersion = c.proto
+	}
+
+	if !c.respondedAt.IsZero() {
+		entry.Timings.Wait = milliseconds(c.respondedAt.Sub(c.startedAt))
+		if !c.finishedAt.IsZero() {
+			entry.Timings.Receive = milliseconds(c.finishedAt.Sub(c.respondedAt))
+		}
+	}
+	entry.Time = entry.Timings.Send + entry.Timings.Wait + entry.Timings.Receive
+	return entry
+}
+
+func (t *trafficRecorder) harHeaders(header http.Header) []harNameValue {
+	headers := []harNameValue{}
+	for name, values := range header {
+		for _, value := range values {
+			if harRedactedHeaders[http.CanonicalHeaderKey(name)] {
+				value = harRedacted
+			}
+			headers = append(headers, harNameValue{Name: name, Value: t.redact(value)})
+		}
+	}
+	return headers
+}
+
+// harURL redacts the values of query parameters carrying credentials and the secrets in a URL.
+// The query is rewritten in place rather than re-encoded, so the URL stays as it was sent.
+func (t *trafficRecorder) harURL(rawURL string) string {
+	base, query, found := strings.Cut(rawURL, "?")
+	if !found {
+		return t.redact(rawURL)
+	}
+	query, fragment, hasFragment := strings.Cut(query, "#")
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		name, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil && harRedactedQueryParams[strings.ToLower(unescaped)] {
+			params[i] = name + "=" + harRedacted
+		}
+	}
+	redacted := base + "?" + strings.Join(params, "&")
+	if hasFragment {
+		redacted += "#" + fragment
+	}
+	return t.redact(redacted)
+}
+
+// harText returns a body as text, base64 encoded if it is not valid UTF-8. The start of a secret
+// cut off by the capture limit is redacted as well, taking the longest start of any secret so a
+// secret sharing its first bytes with another one is not left partly visible.
+func (t *trafficRecorder) harText(body []byte, truncated bool) (string, string) {
+	for _, secret := range t.harSecrets {
+		body = bytes.ReplaceAll(body, []byte(secret), []byte(harRedacted))
+	}
+	if truncated {
+		cut := 0
+		for _, secret := range t.harSecrets {
+			for n := min(len(secret)-1, len(body)); n > cut; n-- {
+				if bytes.HasSuffix(body, []byte(secret[:n])) {
+					cut = n
+					break
+				}
+			}
+		}
+		if cut > 0 {
+			body = append(body[:len(body)-cut], harRedacted...)
+		}
+	}
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func (t *trafficRecorder) redact(value string) string {
+	for _, secret := range t.harSecrets {
+		value = strings.ReplaceAll(value, secret, harRedacted)
+	}
+	return value
+}
+
+func harBodyComment(encoding string, truncated bool, captured int) string {
+	var notes []string
+	if encoding != "" {
+		notes = append(notes, encoding+" encoded")
+	}
+	if truncated {
+		notes = append(notes, fmt.Sprintf("truncated to the first %d bytes", captured))
+	}
+	return strings.Join(notes, ", ")
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// The har* types follow the HAR 1.2 specification, http://www.softwareishard.com/blog/har-12-spec/.
+type harLog struct {
+	Log harLogBody `json:"log"`
+}
+
+type harLogBody struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harCookie    `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harCookie    `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+// harCookie is never filled in, cookies are redacted along with their headers.
+type harCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
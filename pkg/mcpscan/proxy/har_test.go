@@ -0,0 +1,151 @@
+package proxy_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/elazarl/goproxy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
+)
+
+type testHARHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type testHAR struct {
+	Log struct {
+		Version string `json:"version"`
+		Entries []struct {
+			Comment string `json:"comment"`
+			Request struct {
+				Method      string          `json:"method"`
+				URL         string          `json:"url"`
+				Headers     []testHARHeader `json:"headers"`
+				QueryString []testHARHeader `json:"queryString"`
+				BodySize    int64           `json:"bodySize"`
+				PostData    *struct {
+					Text    string `json:"text"`
+					Comment string `json:"comment"`
+				} `json:"postData"`
+			} `json:"request"`
+			Response struct {
+				Status     int    `json:"status"`
+				StatusText string `json:"statusText"`
+				Content    struct {
+					Size int64  `json:"size"`
+					Text string `json:"text"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// headerInjector adds headers to every request, as the interceptors authenticating requests do.
+type headerInjector struct{}
+
+func (headerInjector) GetCondition() goproxy.ReqCondition {
+	return goproxy.ReqConditionFunc(func(*http.Request, *goproxy.ProxyCtx) bool { return true })
+}
+
+func (headerInjector) GetHandler() goproxy.FuncReqHandler {
+	return func(req *http.Request, _ *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+		req.Header.Set("User-Agent", "snyk-cli/1.1.1")
+		req.Header.Set("X-Api-Key", "injected-secret")
+		return req, nil
+	}
+}
+
+func Test_writesHAR(t *testing.T) {
+	basecache := "testcache"
+	version := "1.1.1"
+	clientID := "00000000-0000-4000-8000-000000000001"
+
+	config := setup(t, basecache, version)
+	defer teardown(t, basecache)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "cookie-secret"})
+		_, _ = io.WriteString(w, `{"result":"ok"}`)
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	egress, err := proxy.NewEgressPolicy(proxy.EgressBlock, []string{serverURL.Host})
+	require.NoError(t, err)
+	wp, err := proxy.NewWrapperProxy(config, version, &debugLogger, caData)
+	require.NoError(t, err)
+	wp.SetUpstreamProxy(func(*http.Request) (*url.URL, error) { return nil, nil })
+	wp.SetEgressPolicy(egress)
+	wp.RegisterInterceptor(headerInjector{})
+	// The other secret starts like the client ID, which must still be redacted where it is cut off
+	wp.CaptureHAR(16, "0-other-secret", clientID)
+	require.NoError(t, wp.Start())
+	defer wp.Close()
+	password := wp.ProxyInfo().Password
+
+	proxiedClient, err := helper_getHttpClient(wp, true)
+	require.NoError(t, err)
+
+	body := `{"client":"` + clientID + `","password":"` + password + `"}`
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/push?client="+clientID+"&api_key=query-secret&page=2", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "token snyk-secret")
+	req.Header.Set("x-client-id", clientID)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := proxiedClient.Do(req)
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	_, err = proxiedClient.Get("https://telemetry.example.test/v1/events")
+	assert.Error(t, err)
+
+	var buffer bytes.Buffer
+	require.NoError(t, wp.WriteHAR(&buffer))
+	raw := buffer.String()
+	for _, secret := range []string{clientID, password, "snyk-secret", "cookie-secret", "query-secret", "injected-secret"} {
+		assert.NotContains(t, raw, secret)
+	}
+
+	var har testHAR
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &har))
+	assert.Equal(t, "1.2", har.Log.Version)
+	require.Len(t, har.Log.Entries, 2)
+
+	push := har.Log.Entries[0]
+	assert.Equal(t, http.MethodPost, push.Request.Method)
+	assert.Equal(t, server.URL+"/push?client=***&api_key=***&page=2", push.Request.URL)
+	assert.Contains(t, push.Request.QueryString, testHARHeader{Name: "api_key", Value: "***"})
+	assert.Contains(t, push.Request.QueryString, testHARHeader{Name: "page", Value: "2"})
+	assert.Equal(t, int64(len(body)), push.Request.BodySize)
+	require.NotNil(t, push.Request.PostData)
+	assert.Equal(t, `{"client":"***`, push.Request.PostData.Text)
+	assert.Equal(t, "truncated to the first 16 bytes", push.Request.PostData.Comment)
+	for _, name := range []string{"Authorization", "X-Client-Id", "X-Api-Key", "Proxy-Authorization"} {
+		assert.Contains(t, push.Request.Headers, testHARHeader{Name: name, Value: "***"})
+	}
+	// Headers are captured as the request is sent on, after the interceptors added theirs
+	assert.Contains(t, push.Request.Headers, testHARHeader{Name: "User-Agent", Value: "snyk-cli/1.1.1"})
+	assert.Equal(t, http.StatusOK, push.Response.Status)
+	assert.Equal(t, "OK", push.Response.StatusText)
+	assert.Equal(t, int64(15), push.Response.Content.Size)
+	assert.Equal(t, `{"result":"ok"}`, push.Response.Content.Text)
+
+	blocked := har.Log.Entries[1]
+	assert.Equal(t, http.MethodConnect, blocked.Request.Method)
+	assert.Equal(t, "https://telemetry.example.test:443", blocked.Request.URL)
+	assert.Equal(t, "Blocked by the egress policy", blocked.Response.StatusText)
+	assert.Equal(t, "blocked by the egress policy", blocked.Comment)
+}
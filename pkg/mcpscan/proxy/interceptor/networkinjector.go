@@ -2,9 +2,11 @@ package interceptor
 
 import (
 	"net/http"
-	"regexp"
+	"net/url"
+	"strings"
 
 	"github.com/elazarl/goproxy"
+	"github.com/snyk/go-application-framework/pkg/configuration"
 	"github.com/snyk/go-application-framework/pkg/workflow"
 )
 
@@ -17,11 +19,15 @@ func (ni networkInjector) GetCondition() goproxy.ReqCondition {
 	return ni.requestCondition
 }
 
-// GetHandler for networkinjector will re-route all requests from the proxy to the existing networking layer.
-// This ensures that we can implement network-layer logic centrally instead of having logic for the legacycli
-// and the gocli in two different places.
+// GetHandler for networkinjector will re-route requests to Snyk from the proxy to the existing networking layer,
+// which adds the CLI's credentials. This ensures that we can implement network-layer logic centrally instead of
+// having logic for the legacycli and the gocli in two different places.
 func (ni networkInjector) GetHandler() goproxy.FuncReqHandler {
 	return func(req *http.Request, proxyCtx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+		// The credentials for this proxy are meant for the proxy only and must not be forwarded.
+		req.Header.Del("Proxy-Authorization")
+		req.Header.Del("Proxy-Connection")
+
 		resp, err := ni.invocationCtx.GetNetworkAccess().GetRoundTripper().RoundTrip(req)
 		if err != nil {
 			ni.invocationCtx.GetEnhancedLogger().Trace().Msgf("intercepting call failed with error: %v", err)
@@ -36,10 +42,61 @@ func (ni networkInjector) GetHandler() goproxy.FuncReqHandler {
 	}
 }
 
+// NewNetworkInjector intercepts requests to the Snyk API only. The MCP servers launched by the scanner
+// inherit its proxy settings, so all other requests are forwarded by the proxy as they are and never
+// pass through the authenticated networking layer; only the upstream proxy authentication applies to them.
 func NewNetworkInjector(invocationCtx workflow.InvocationContext) Interceptor {
 	i := networkInjector{
-		requestCondition: goproxy.UrlMatches(regexp.MustCompile(".*")),
-		invocationCtx:    invocationCtx,
+		requestCondition: goproxy.ReqConditionFunc(func(req *http.Request, _ *goproxy.ProxyCtx) bool {
+			return IsSnykURL(invocationCtx.GetConfiguration(), req.URL)
+		}),
+		invocationCtx: invocationCtx,
 	}
 	return i
 }
+
+// IsSnykURL reports whether u belongs to the configured Snyk API or to one of the additional
+// authenticated URLs. Scheme, host and port must match exactly, so hosts like
+// api.snyk.io.example.com never qualify.
+func IsSnykURL(config configuration.Configuration, u *url.URL) bool {
+	if u == nil {
+		return false
+	}
+	prefixes := append([]string{config.GetString(configuration.API_URL)}, config.GetStringSlice(configuration.AUTHENTICATION_ADDITIONAL_URLS)...)
+	for _, prefix := range prefixes {
+		if matchesURLPrefix(u, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesURLPrefix compares the origin of u with the prefix URL and requires u's path to be
+// inside the prefix's path.
+func matchesURLPrefix(u *url.URL, prefix string) bool {
+	base, err := url.Parse(prefix)
+	if err != nil || base.Host == "" {
+		return false
+	}
+	if !strings.EqualFold(u.Scheme, base.Scheme) ||
+		!strings.EqualFold(u.Hostname(), base.Hostname()) ||
+		effectivePort(u) != effectivePort(base) {
+		return false
+	}
+	basePath := strings.TrimSuffix(base.Path, "/")
+	return basePath == "" || u.Path == basePath || strings.HasPrefix(u.Path, basePath+"/")
+}
+
+func effectivePort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	default:
+		return ""
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/rs/zerolog"
+	"github.com/snyk/go-application-framework/pkg/configuration"
 	"github.com/snyk/go-application-framework/pkg/mocks"
 
 	"github.com/elazarl/goproxy"
@@ -53,3 +54,41 @@ func TestNetworkInjector_ErrorHandling(t *testing.T) {
 	// Goproxy will send the request again if the response is nil, why it's imperative this does not happen.
 	assert.Nil(t, resp, "response should not be nil when RoundTrip returns an error")
 }
+
+func TestNetworkInjector_Condition(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	config := configuration.NewWithOpts()
+	config.Set(configuration.API_URL, "https://api.snyk.io")
+	config.Set(configuration.AUTHENTICATION_ADDITIONAL_URLS, []string{"https://mirror.example.com/snyk"})
+
+	invocationCtxMock := mocks.NewMockInvocationContext(ctrl)
+	invocationCtxMock.EXPECT().GetConfiguration().Return(config).AnyTimes()
+	condition := NewNetworkInjector(invocationCtxMock).GetCondition()
+
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{url: "https://api.snyk.io/hidden/mcp-scan/push?version=2025-08-28", expected: true},
+		{url: "https://API.snyk.io:443/rest/self", expected: true},
+		{url: "https://mirror.example.com/snyk/v1/test", expected: true},
+		{url: "https://mirror.example.com/snyk", expected: true},
+		{url: "https://api.snyk.io.example.com/hidden/mcp-scan/push", expected: false},
+		{url: "https://example.com/api.snyk.io", expected: false},
+		{url: "https://evilapi.snyk.io/rest/self", expected: false},
+		{url: "http://api.snyk.io/rest/self", expected: false},
+		{url: "https://api.snyk.io:8443/rest/self", expected: false},
+		{url: "https://mirror.example.com/snyk-other", expected: false},
+		{url: "https://mirror.example.com/", expected: false},
+		{url: "https://registry.npmjs.org/some-mcp-server", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, http.NoBody)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, condition.HandleReq(req, &goproxy.ProxyCtx{}))
+		})
+	}
+}
@@ -0,0 +1,185 @@
+//go:build darwin
+
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// ProcessAttributionSupported reports whether requests can be attributed to the processes that sent them.
+const ProcessAttributionSupported = true
+
+// maxProcessDepth bounds the walk from a client process up to the CLI.
+const maxProcessDepth = 32
+
+// lsofTimeout bounds the socket owner lookup of a single connection.
+const lsofTimeout = 5 * time.Second
+
+// clientProcesses finds the process that opened the connection from remoteAddr to the proxy
+// listening on proxyPort, followed by its ancestors up to the direct child of this process. macOS
+// has no /proc, so the process tree is read with sysctl and the socket owner is looked up with
+// lsof among the processes started by this process. It returns nil if the process cannot be found.
+func clientProcesses(remoteAddr string, proxyPort int) []Process {
+	addr, err := netip.ParseAddrPort(remoteAddr)
+	if err != nil {
+		return nil
+	}
+	parents, err := processParents()
+	if err != nil {
+		return nil
+	}
+	root := os.Getpid()
+	pids := descendants(parents, root)
+	if len(pids) == 0 {
+		return nil
+	}
+	pid, err := socketOwner(int(addr.Port()), proxyPort, pids)
+	if err != nil {
+		return nil
+	}
+	return processChain(pid, root, parents)
+}
+
+// processParents maps the pid of every running process to the pid of its parent.
+func processParents() (map[int]int, error) {
+	procs, err := unix.SysctlKinfoProcSlice("kern.proc.all")
+	if err != nil {
+		return nil, err
+	}
+	parents := make(map[int]int, len(procs))
+	for i := range procs {
+		parents[int(procs[i].Proc.P_pid)] = int(procs[i].Eproc.Ppid)
+	}
+	return parents, nil
+}
+
+// descendants returns the processes started by root, directly or through other processes,
+// closest first.
+func descendants(parents map[int]int, root int) []int {
+	children := map[int][]int{}
+	for pid, ppid := range parents {
+		if pid != ppid {
+			children[ppid] = append(children[ppid], pid)
+		}
+	}
+
+	var result []int
+	queue := children[root]
+	slices.Sort(queue)
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		result = append(result, pid)
+		next := children[pid]
+		slices.Sort(next)
+		queue = append(queue, next...)
+	}
+	return result
+}
+
+// socketOwner asks lsof which of pids has the TCP socket connected from localPort to remotePort open.
+func socketOwner(localPort, remotePort int, pids []int) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), lsofTimeout)
+	defer cancel()
+	list := make([]string, len(pids))
+	for i, pid := range pids {
+		list[i] = strconv.Itoa(pid)
+	}
+	// lsof exits with an error when none of the processes has a matching socket, so only its
+	// output is relevant.
+	//nolint:gosec // the arguments are ports and process IDs
+	output, _ := exec.CommandContext(ctx, "lsof", "-nP", "-a", "-p", strings.Join(list, ","), "-iTCP:"+strconv.Itoa(localPort), "-Fpn").Output()
+	return parseSocketOwner(output, localPort, remotePort)
+}
+
+// parseSocketOwner finds the process of the socket connected from localPort to remotePort in
+// lsof field output, where "p" lines start a process and "n" lines name its files, e.g.
+// "127.0.0.1:53211->127.0.0.1:8080".
+func parseSocketOwner(output []byte, localPort, remotePort int) (int, error) {
+	pid := 0
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		switch line[0] {
+		case 'p':
+			pid, _ = strconv.Atoi(line[1:])
+		case 'n':
+			local, remote, found := strings.Cut(line[1:], "->")
+			if found && pid > 0 && strings.HasSuffix(local, ":"+strconv.Itoa(localPort)) && strings.HasSuffix(remote, ":"+strconv.Itoa(remotePort)) {
+				return pid, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("no process owns the socket from port %d to port %d", localPort, remotePort)
+}
+
+// processChain returns pid and its ancestors up to the direct child of root, nil if pid does not
+// descend from root.
+func processChain(pid, root int, parents map[int]int) []Process {
+	var chain []Process
+	for range maxProcessDepth {
+		if pid <= 1 || pid == root {
+			return nil
+		}
+		ppid, ok := parents[pid]
+		if !ok {
+			return nil
+		}
+		chain = append(chain, Process{PID: pid, Args: commandLine(pid)})
+		if ppid == root {
+			return chain
+		}
+		pid = ppid
+	}
+	return nil
+}
+
+func commandLine(pid int) []string {
+	data, err := unix.SysctlRaw("kern.procargs2", pid)
+	if err != nil {
+		return nil
+	}
+	return parseProcArgs(data)
+}
+
+// parseProcArgs parses the kern.procargs2 layout: the argument count, the executable path padded
+// with NUL bytes, then the NUL separated arguments followed by the environment.
+func parseProcArgs(data []byte) []string {
+	if len(data) < 4 {
+		return nil
+	}
+	argc := int(binary.LittleEndian.Uint32(data))
+	rest := data[4:]
+	end := bytes.IndexByte(rest, 0)
+	if end < 0 {
+		return nil
+	}
+	rest = bytes.TrimLeft(rest[end:], "\x00")
+
+	args := make([]string, 0, argc)
+	for len(args) < argc && len(rest) > 0 {
+		end := bytes.IndexByte(rest, 0)
+		if end < 0 {
+			end = len(rest)
+		}
+		args = append(args, string(rest[:end]))
+		rest = rest[min(end+1, len(rest)):]
+	}
+	return args
+}
@@ -0,0 +1,58 @@
+//go:build darwin
+
+package proxy //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSocketOwner(t *testing.T) {
+	output := []byte("p100\nf3\nn127.0.0.1:8080->127.0.0.1:53211\np200\nf7\nn*:9000\nf8\nn[::1]:53211->[::1]:8080\n")
+
+	pid, err := parseSocketOwner(output, 53211, 8080)
+	require.NoError(t, err)
+	assert.Equal(t, 200, pid, "the socket connected from the client port belongs to the client")
+
+	_, err = parseSocketOwner(output, 53212, 8080)
+	require.Error(t, err)
+}
+
+func TestParseProcArgs(t *testing.T) {
+	data := append([]byte{2, 0, 0, 0}, "/usr/bin/node\x00\x00\x00\x00node\x00/opt/mcp/server.js\x00HOME=/Users/dev\x00"...)
+	assert.Equal(t, []string{"node", "/opt/mcp/server.js"}, parseProcArgs(data))
+	assert.Nil(t, parseProcArgs([]byte{1}))
+}
+
+func TestClientProcesses(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	proxyPort := listener.Addr().(*net.TCPAddr).Port
+
+	// The socket of the test process itself is not attributed, as it does not descend from itself
+	assert.Nil(t, clientProcesses(conn.LocalAddr().String(), proxyPort))
+
+	// A child holding the socket is found
+	socket, err := conn.(*net.TCPConn).File()
+	require.NoError(t, err)
+	defer socket.Close()
+	cmd := exec.Command("sleep", "30")
+	cmd.ExtraFiles = []*os.File{socket}
+	require.NoError(t, cmd.Start())
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	assert.Equal(t, []Process{{PID: cmd.Process.Pid, Args: []string{"sleep", "30"}}}, clientProcesses(conn.LocalAddr().String(), proxyPort))
+}
@@ -0,0 +1,168 @@
+//go:build linux
+
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ProcessAttributionSupported reports whether requests can be attributed to the processes that sent them.
+const ProcessAttributionSupported = true
+
+// maxProcessDepth bounds the walk from a client process up to the CLI.
+const maxProcessDepth = 32
+
+// clientProcesses finds the process that opened the connection from remoteAddr to the proxy
+// listening on proxyPort, followed by its ancestors up to the direct child of this process. It
+// returns nil if the process cannot be found or was not started by this process.
+func clientProcesses(remoteAddr string, proxyPort int) []Process {
+	addr, err := netip.ParseAddrPort(remoteAddr)
+	if err != nil {
+		return nil
+	}
+	inode, err := socketInode(addr.Port(), uint16(proxyPort)) //nolint:gosec // ports fit into 16 bits
+	if err != nil {
+		return nil
+	}
+	root := os.Getpid()
+	pid, err := socketOwner(inode, root)
+	if err != nil {
+		return nil
+	}
+	return processChain(pid, root)
+}
+
+// socketInode finds the inode of the local TCP socket connected from localPort to remotePort.
+func socketInode(localPort, remotePort uint16) (string, error) {
+	for _, table := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		data, err := os.ReadFile(table)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Scan() // header
+		for scanner.Scan() {
+			// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 10 {
+				continue
+			}
+			if hexPort(fields[1]) == localPort && hexPort(fields[2]) == remotePort {
+				return fields[9], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no socket from port %d to port %d", localPort, remotePort)
+}
+
+func hexPort(address string) uint16 {
+	_, port, found := strings.Cut(address, ":")
+	if !found {
+		return 0
+	}
+	value, err := strconv.ParseUint(port, 16, 16)
+	if err != nil {
+		return 0
+	}
+	return uint16(value)
+}
+
+// socketOwner finds a descendant of root with an open file descriptor for the socket inode. Only
+// the descriptors of processes started by root are inspected, as only those are attributed.
+func socketOwner(inode string, root int) (int, error) {
+	target := "socket:[" + inode + "]"
+	for _, pid := range descendants(root) {
+		fds, err := filepath.Glob(fmt.Sprintf("/proc/%d/fd/*", pid))
+		if err != nil {
+			return 0, err
+		}
+		for _, fd := range fds {
+			if link, linkErr := os.Readlink(fd); linkErr == nil && link == target {
+				return pid, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("no process started by %d owns socket %s", root, inode)
+}
+
+// descendants returns the processes started by root, directly or through other processes,
+// closest first.
+func descendants(root int) []int {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	children := map[int][]int{}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		if ppid, err := parentPID(pid); err == nil {
+			children[ppid] = append(children[ppid], pid)
+		}
+	}
+
+	var result []int
+	queue := children[root]
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		result = append(result, pid)
+		queue = append(queue, children[pid]...)
+	}
+	return result
+}
+
+// processChain returns pid and its ancestors up to the direct child of root, nil if pid does not
+// descend from root.
+func processChain(pid, root int) []Process {
+	var chain []Process
+	for range maxProcessDepth {
+		if pid <= 1 || pid == root {
+			return nil
+		}
+		ppid, err := parentPID(pid)
+		if err != nil {
+			return nil
+		}
+		chain = append(chain, Process{PID: pid, Args: commandLine(pid)})
+		if ppid == root {
+			return chain
+		}
+		pid = ppid
+	}
+	return nil
+}
+
+func parentPID(pid int) (int, error) {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, err
+	}
+	// The command name in parentheses may contain spaces, the fields after it are "state ppid ..."
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, fmt.Errorf("malformed stat of process %d", pid)
+	}
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("malformed stat of process %d", pid)
+	}
+	return strconv.Atoi(fields[1])
+}
+
+func commandLine(pid int) []string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+}
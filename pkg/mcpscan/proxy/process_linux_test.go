@@ -0,0 +1,81 @@
+//go:build linux
+
+package proxy //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSocketOwner(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	localPort := uint16(conn.LocalAddr().(*net.TCPAddr).Port) //nolint:gosec // ports fit into 16 bits
+	remotePort := uint16(listener.Addr().(*net.TCPAddr).Port) //nolint:gosec // ports fit into 16 bits
+	inode, err := socketInode(localPort, remotePort)
+	require.NoError(t, err)
+
+	// The socket of the test process itself is not attributed, as it does not descend from itself
+	_, err = socketOwner(inode, os.Getpid())
+	require.Error(t, err)
+	assert.Nil(t, clientProcesses(conn.LocalAddr().String(), int(remotePort)))
+
+	// A child holding the socket is found
+	socket, err := conn.(*net.TCPConn).File()
+	require.NoError(t, err)
+	defer socket.Close()
+	cmd := exec.Command("sleep", "30")
+	cmd.ExtraFiles = []*os.File{socket}
+	require.NoError(t, cmd.Start())
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	pid, err := socketOwner(inode, os.Getpid())
+	require.NoError(t, err)
+	assert.Equal(t, cmd.Process.Pid, pid)
+}
+
+func TestDescendants(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "sleep 30 & wait")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	require.NoError(t, cmd.Start())
+	defer func() {
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		_ = cmd.Wait()
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(descendants(os.Getpid())) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, cmd.Process.Pid, descendants(os.Getpid())[0], "the direct child comes first")
+}
+
+func TestProcessChain(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	require.NoError(t, cmd.Start())
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	chain := processChain(cmd.Process.Pid, os.Getpid())
+	require.Len(t, chain, 1)
+	assert.Equal(t, Process{PID: cmd.Process.Pid, Args: []string{"sleep", "30"}}, chain[0])
+
+	assert.Nil(t, processChain(cmd.Process.Pid, -1), "processes not started by root are not attributed")
+}
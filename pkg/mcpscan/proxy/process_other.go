@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package proxy
+
+// ProcessAttributionSupported reports whether requests can be attributed to the processes that sent them.
+const ProcessAttributionSupported = false
+
+// clientProcesses cannot attribute connections to processes on this platform.
+func clientProcesses(string, int) []Process {
+	return nil
+}
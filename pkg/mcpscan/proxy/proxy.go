@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
@@ -41,6 +42,8 @@ type WrapperProxy struct {
 	proxyPassword       string
 	config              configuration.Configuration
 	interceptors        []interceptor.Interceptor
+	egress              *EgressPolicy
+	traffic             *trafficRecorder
 }
 
 type ProxyInfo struct {
@@ -54,6 +57,13 @@ const (
 	PROXY_USERNAME = "snykcli"
 )
 
+// The proxy certificate gets a name of its own rather than the Snyk CLI's "snyk-cli-cert-*.crt",
+// so leftovers of this extension can be swept without touching certificates the CLI is using.
+const (
+	CertFilePrefix = "snyk-mcp-scan-cert-"
+	CertFileSuffix = ".crt"
+)
+
 type CaData struct {
 	CertPool *x509.CertPool
 	CertFile string
@@ -75,7 +85,7 @@ func InitCA(config configuration.Configuration, cliVersion string, logger *zerol
 	if err != nil {
 		return nil, err
 	}
-	certFile, err := os.CreateTemp(tmpDirectory, "snyk-cli-cert-*.crt")
+	certFile, err := os.CreateTemp(tmpDirectory, CertFilePrefix+"*"+CertFileSuffix)
 	if err != nil {
 		logger.Println("failed to create temp cert file")
 		return nil, err
@@ -148,10 +158,16 @@ func NewWrapperProxy(config configuration.Configuration, cliVersion string, debu
 		},
 	}
 
+	// Requests the network injector does not send through the CLI's networking layer use the
+	// transport directly, so it authenticates with the upstream proxy the same way (e.g. Negotiate).
+	p.authMechanism = httpauth.AuthenticationMechanismFromString(config.GetString(configuration.PROXY_AUTHENTICATION_MECHANISM))
 	p.SetUpstreamProxy(http.ProxyFromEnvironment)
 
 	p.proxyUsername = PROXY_USERNAME
 	p.proxyPassword = uuid.New().String()
+	p.traffic = newTrafficRecorder(func(remoteAddr string) []Process {
+		return clientProcesses(remoteAddr, p.port)
+	})
 
 	return &p, nil
 }
@@ -190,6 +206,13 @@ func (p *WrapperProxy) HandleConnect(req string, ctx *goproxy.ProxyCtx) (*goprox
 		action = goproxy.OkConnect
 	}
 
+	if action == goproxy.OkConnect && !p.egress.permit(req, "443") {
+		p.DebugLogger.Printf("Blocked connection to %s by the egress policy", req)
+		host := hostWithPort(req, "https")
+		p.traffic.add(ctx.Req.RemoteAddr, http.MethodConnect, host, "https://"+host, ctx.Req.Proto, nil).blocked.Store(true)
+		return goproxy.RejectConnect, req
+	}
+
 	if action == goproxy.OkConnect {
 		action, str = goproxy.AlwaysMitm.HandleConnect(req, ctx)
 	}
@@ -203,12 +226,18 @@ func (p *WrapperProxy) Start() error {
 	// zerolog based logger also works but it will print empty lines between logs
 	proxy.Logger = log.New(&pkg_utils.ToZeroLogDebug{Logger: p.DebugLogger}, "", 0)
 
+	// Requests are recorded first and the egress policy runs next, so that rejected requests are
+	// recorded but never reach the interceptors
+	proxy.OnRequest().DoFunc(p.traffic.handleRequest)
+	proxy.OnRequest().DoFunc(p.handleEgress)
 	for _, i := range p.interceptors {
 		proxy.OnRequest(i.GetCondition()).DoFunc(i.GetHandler())
 	}
+	proxy.OnRequest().DoFunc(p.traffic.captureRequestHeaders)
 
 	proxy.OnRequest().HandleConnect(p)
 	proxy.OnResponse().DoFunc(p.handleResponse)
+	proxy.OnResponse().DoFunc(p.traffic.handleResponse)
 	proxy.Verbose = true
 	proxyServer := &http.Server{
 		Handler: proxy,
@@ -227,19 +256,28 @@ func (p *WrapperProxy) Start() error {
 	p.DebugLogger.Print("Wrapper proxy is listening on port: ", p.port)
 
 	go func() {
-		_ = p.httpServer.Serve(l) // this blocks until the server stops and gives you an error which can be ignored
+		_ = p.httpServer.Serve(p.traffic.listen(l)) // this blocks until the server stops and gives you an error which can be ignored
 	}()
 
 	return nil
 }
 
+// shutdownTimeout bounds how long Stop waits for in-flight requests before closing their connections.
+const shutdownTimeout = 5 * time.Second
+
 func (p *WrapperProxy) Stop() {
-	err := p.httpServer.Shutdown(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := p.httpServer.Shutdown(ctx)
 	if err == nil {
 		p.DebugLogger.Printf("Proxy successfully shut down")
 	} else {
 		// Error from closing listeners, or context timeout:
 		p.DebugLogger.Printf("HTTP server Shutdown error: %v", err)
+		if closeErr := p.httpServer.Close(); closeErr != nil {
+			p.DebugLogger.Printf("HTTP server Close error: %v", closeErr)
+		}
 	}
 }
 
@@ -267,6 +305,21 @@ func (p *WrapperProxy) RegisterInterceptor(interceptor interceptor.Interceptor)
 	p.interceptors = append(p.interceptors, interceptor)
 }
 
+// SetEgressPolicy restricts the destinations reachable through the proxy; it must be set before Start.
+func (p *WrapperProxy) SetEgressPolicy(policy *EgressPolicy) {
+	p.egress = policy
+}
+
+// Traffic returns the requests that passed through the proxy so far, including rejected ones.
+func (p *WrapperProxy) Traffic() []Exchange {
+	return p.traffic.Exchanges()
+}
+
+// EgressPolicy returns the egress policy of the proxy, nil if every destination is allowed.
+func (p *WrapperProxy) EgressPolicy() *EgressPolicy {
+	return p.egress
+}
+
 func (p *WrapperProxy) SetUpstreamProxyAuthentication(mechanism httpauth.AuthenticationMechanism) {
 	if mechanism != p.authMechanism {
 		p.authMechanism = mechanism
@@ -8,14 +8,17 @@ import (
 	"io/fs"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"strings"
 	"sync"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	"github.com/rs/zerolog"
 	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/mocks"
 	"github.com/snyk/go-application-framework/pkg/networking/certs"
 	gafUtils "github.com/snyk/go-application-framework/pkg/utils"
 	"github.com/snyk/go-httpauth/pkg/httpauth"
@@ -23,6 +26,7 @@ import (
 
 	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/constants"
 	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy/interceptor"
 	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/utils"
 )
 
@@ -286,6 +290,34 @@ func Test_SetUpstreamProxy(t *testing.T) {
 	}
 }
 
+func Test_NewWrapperProxy_UpstreamProxyAuthentication(t *testing.T) {
+	basecache := "testcache"
+	version := "1.1.1"
+
+	config := setup(t, basecache, version)
+	defer teardown(t, basecache)
+
+	for mechanism, authenticated := range map[httpauth.AuthenticationMechanism]bool{
+		httpauth.AnyAuth:   true,
+		httpauth.Negotiate: true,
+		httpauth.NoAuth:    false,
+	} {
+		config.Set(configuration.PROXY_AUTHENTICATION_MECHANISM, httpauth.StringFromAuthenticationMechanism(mechanism))
+		wp, err := proxy.NewWrapperProxy(config, version, &debugLogger, caData)
+		assert.NoError(t, err)
+
+		// The transport forwarding requests that do not go to Snyk authenticates with the upstream proxy
+		transport := wp.Transport()
+		if authenticated {
+			assert.NotNil(t, transport.DialContext, mechanism)
+			assert.Nil(t, transport.Proxy, mechanism)
+		} else {
+			assert.Nil(t, transport.DialContext, mechanism)
+			assert.NotNil(t, transport.Proxy, mechanism)
+		}
+	}
+}
+
 func Test_AddExtraCaCert(t *testing.T) {
 	basecache := "testcache"
 	version := "1.1.1"
@@ -317,3 +349,76 @@ func Test_AddExtraCaCert(t *testing.T) {
 	// cleanup
 	os.Remove(file.Name())
 }
+
+// authRoundTripper stands in for the CLI's networking layer, which authenticates requests to Snyk.
+type authRoundTripper struct{}
+
+func (authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "token snyk-secret")
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func Test_credentialsOnlyReachSnyk(t *testing.T) {
+	basecache := "testcache"
+	version := "1.1.1"
+
+	config := setup(t, basecache, version)
+	defer teardown(t, basecache)
+
+	authorizations := make(chan string, 1)
+	newServer := func() *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorizations <- r.Header.Get("Authorization") + r.Header.Get("Proxy-Authorization")
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	snykServer := newServer()
+	otherServer := newServer()
+	config.Set(configuration.API_URL, snykServer.URL)
+
+	ctrl := gomock.NewController(t)
+	networkAccess := mocks.NewMockNetworkAccess(ctrl)
+	networkAccess.EXPECT().GetRoundTripper().Return(authRoundTripper{}).AnyTimes()
+	invocationCtx := mocks.NewMockInvocationContext(ctrl)
+	invocationCtx.EXPECT().GetConfiguration().Return(config).AnyTimes()
+	invocationCtx.EXPECT().GetNetworkAccess().Return(networkAccess).AnyTimes()
+	invocationCtx.EXPECT().GetEnhancedLogger().Return(&debugLogger).AnyTimes()
+
+	wp, err := proxy.NewWrapperProxy(config, version, &debugLogger, caData)
+	assert.Nil(t, err)
+	wp.SetUpstreamProxy(func(*http.Request) (*url.URL, error) { return nil, nil })
+	wp.RegisterInterceptor(interceptor.NewNetworkInjector(invocationCtx))
+	assert.Nil(t, wp.Start())
+	defer wp.Close()
+
+	proxiedClient, err := helper_getHttpClient(wp, true)
+	assert.Nil(t, err)
+
+	otherURL, err := url.Parse(otherServer.URL)
+	assert.Nil(t, err)
+	snykURL, err := url.Parse(snykServer.URL)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{name: "Snyk API", url: snykServer.URL + "/rest/self", expected: "token snyk-secret"},
+		{name: "third-party host", url: otherServer.URL + "/tools", expected: ""},
+		{name: "Snyk API port on another host name", url: "http://localhost:" + snykURL.Port() + "/rest/self", expected: ""},
+		{name: "Snyk API path on a third-party host", url: "http://localhost:" + otherURL.Port() + "/" + snykURL.Host + "/rest/self", expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := proxiedClient.Get(tt.url)
+			if !assert.Nil(t, err) {
+				return
+			}
+			res.Body.Close()
+			assert.Equal(t, tt.expected, <-authorizations)
+		})
+	}
+}
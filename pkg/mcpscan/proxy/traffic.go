@@ -0,0 +1,261 @@
+package proxy
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elazarl/goproxy"
+)
+
+// Process is a process that sent requests through the proxy.
+type Process struct {
+	PID  int
+	Args []string
+}
+
+// Exchange is a request that passed through the proxy, or was rejected by it.
+type Exchange struct {
+	// Processes is the requesting process followed by its ancestors up to the process started by
+	// the CLI. It is empty if the process could not be determined, which is the case outside Linux.
+	Processes     []Process
+	Method        string
+	Host          string
+	StatusCode    int
+	BytesSent     int64
+	BytesReceived int64
+	Blocked       bool
+}
+
+// exchange is the record of an Exchange while its bodies are still being transferred.
+type exchange struct {
+	processes     []Process
+	method        string
+	host          string
+	statusCode    int
+	blocked       atomic.Bool
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+	// har is only set when capturing for a HAR file.
+	har *harCapture
+}
+
+// trafficRecorder records every request passing through the proxy and the process that sent it.
+type trafficRecorder struct {
+	mu        sync.Mutex
+	exchanges []*exchange
+	// processes caches the processes by client address, as all requests of a connection come from
+	// the same process and the process may exit before its connection is closed. Entries are
+	// removed once the connection is closed, see listen.
+	processes map[string][]Process
+	// lookup finds the processes for a client address, see clientProcesses.
+	lookup func(remoteAddr string) []Process
+
+	captureHAR   bool
+	harBodyLimit int64
+	harSecrets   []string
+}
+
+func newTrafficRecorder(lookup func(remoteAddr string) []Process) *trafficRecorder {
+	return &trafficRecorder{processes: map[string][]Process{}, lookup: lookup}
+}
+
+func (t *trafficRecorder) clientProcesses(remoteAddr string) []Process {
+	t.mu.Lock()
+	processes, ok := t.processes[remoteAddr]
+	t.mu.Unlock()
+	if ok {
+		return processes
+	}
+
+	processes = t.lookup(remoteAddr)
+	t.mu.Lock()
+	t.processes[remoteAddr] = processes
+	t.mu.Unlock()
+	return processes
+}
+
+// forget drops the processes cached for a client address.
+func (t *trafficRecorder) forget(remoteAddr string) {
+	t.mu.Lock()
+	delete(t.processes, remoteAddr)
+	t.mu.Unlock()
+}
+
+// listen wraps the proxy's listener to forget the processes of a client connection once it is
+// closed, as its address may then be reused by a connection of another process.
+func (t *trafficRecorder) listen(l net.Listener) net.Listener {
+	return &trackingListener{Listener: l, traffic: t}
+}
+
+type trackingListener struct {
+	net.Listener
+	traffic *trafficRecorder
+}
+
+func (l *trackingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &trackedConn{Conn: conn, traffic: l.traffic}, nil
+}
+
+// trackedConn is a client connection, which is also what goproxy hijacks for CONNECT tunnels.
+type trackedConn struct {
+	net.Conn
+	traffic *trafficRecorder
+	once    sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() { c.traffic.forget(c.RemoteAddr().String()) })
+	return err
+}
+
+// add records a request; rawURL, proto and header are only kept when capturing for a HAR file.
+func (t *trafficRecorder) add(remoteAddr, method, host, rawURL, proto string, header http.Header) *exchange {
+	e := &exchange{processes: t.clientProcesses(remoteAddr), method: method, host: host}
+	if t.captureHAR {
+		e.har = &harCapture{
+			startedAt:     time.Now(),
+			url:           rawURL,
+			proto:         proto,
+			requestHeader: header.Clone(),
+			requestBody:   &bodyCapture{limit: t.harBodyLimit},
+		}
+	}
+	t.mu.Lock()
+	t.exchanges = append(t.exchanges, e)
+	t.mu.Unlock()
+	return e
+}
+
+// handleRequest records a request and counts the bytes of its body as it is sent.
+func (t *trafficRecorder) handleRequest(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+	e := t.add(req.RemoteAddr, req.Method, hostWithPort(req.URL.Host, req.URL.Scheme), req.URL.String(), req.Proto, req.Header)
+	ctx.UserData = e
+	var capture *bodyCapture
+	if e.har != nil {
+		capture = e.har.requestBody
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = &countingReader{ReadCloser: req.Body, count: &e.bytesSent, capture: capture}
+	}
+	return req, nil
+}
+
+// captureRequestHeaders replaces the captured headers of a request by those it is sent with,
+// including the authentication and other headers added by the interceptors. It is registered
+// after them; requests answered by the proxy itself keep the headers they were received with.
+func (t *trafficRecorder) captureRequestHeaders(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+	if e, ok := ctx.UserData.(*exchange); ok && e.har != nil {
+		header := req.Header.Clone()
+		t.mu.Lock()
+		e.har.requestHeader = header
+		t.mu.Unlock()
+	}
+	return req, nil
+}
+
+// handleResponse completes the record of the request with the response and counts the bytes of its body.
+func (t *trafficRecorder) handleResponse(resp *http.Response, ctx *goproxy.ProxyCtx) *http.Response {
+	e, ok := ctx.UserData.(*exchange)
+	if !ok || resp == nil {
+		return resp
+	}
+	var capture *bodyCapture
+	var finished func()
+	t.mu.Lock()
+	e.statusCode = resp.StatusCode
+	if e.har != nil {
+		e.har.status, e.har.responseProto, e.har.responseHeader = resp.Status, resp.Proto, resp.Header.Clone()
+		e.har.respondedAt = time.Now()
+		capture = &bodyCapture{limit: t.harBodyLimit}
+		e.har.responseBody = capture
+		finished = func() {
+			t.mu.Lock()
+			e.har.finishedAt = time.Now()
+			t.mu.Unlock()
+		}
+	}
+	t.mu.Unlock()
+	if resp.Body != nil {
+		resp.Body = &countingReader{ReadCloser: resp.Body, count: &e.bytesReceived, capture: capture, finished: finished}
+	}
+	return resp
+}
+
+// markBlocked flags the request of the proxy context as rejected by the egress policy.
+func markBlocked(ctx *goproxy.ProxyCtx) {
+	if e, ok := ctx.UserData.(*exchange); ok {
+		e.blocked.Store(true)
+	}
+}
+
+// Exchanges returns the requests recorded so far in the order they were received.
+func (t *trafficRecorder) Exchanges() []Exchange {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	exchanges := make([]Exchange, 0, len(t.exchanges))
+	for _, e := range t.exchanges {
+		exchanges = append(exchanges, Exchange{
+			Processes:     e.processes,
+			Method:        e.method,
+			Host:          e.host,
+			StatusCode:    e.statusCode,
+			BytesSent:     e.bytesSent.Load(),
+			BytesReceived: e.bytesReceived.Load(),
+			Blocked:       e.blocked.Load(),
+		})
+	}
+	return exchanges
+}
+
+// countingReader counts the bytes read from a body, captures them if capture is set and calls
+// finished once the body is read to the end or closed.
+type countingReader struct {
+	io.ReadCloser
+	count    *atomic.Int64
+	capture  *bodyCapture
+	finished func()
+	once     sync.Once
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.count.Add(int64(n))
+	if r.capture != nil {
+		r.capture.write(p[:n])
+	}
+	if err == io.EOF {
+		r.finish()
+	}
+	return n, err
+}
+
+func (r *countingReader) Close() error {
+	r.finish()
+	return r.ReadCloser.Close()
+}
+
+func (r *countingReader) finish() {
+	if r.finished != nil {
+		r.once.Do(r.finished)
+	}
+}
+
+// hostWithPort adds the default port of the scheme to host if it has none.
+func hostWithPort(host, scheme string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	if scheme == "https" {
+		return net.JoinHostPort(host, "443")
+	}
+	return net.JoinHostPort(host, "80")
+}
@@ -0,0 +1,38 @@
+package proxy //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrafficRecorder_ForgetsClosedConnections(t *testing.T) {
+	var lookups atomic.Int32
+	traffic := newTrafficRecorder(func(string) []Process {
+		lookups.Add(1)
+		return []Process{{PID: 42}}
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	tracked := traffic.listen(listener)
+	defer tracked.Close()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+	conn, err := tracked.Accept()
+	require.NoError(t, err)
+	remoteAddr := conn.RemoteAddr().String()
+
+	traffic.add(remoteAddr, "GET", "example.test:443", "", "", nil)
+	traffic.add(remoteAddr, "GET", "example.test:443", "", "", nil)
+	assert.Equal(t, int32(1), lookups.Load(), "requests of a connection share the lookup")
+
+	require.NoError(t, conn.Close())
+	traffic.add(remoteAddr, "GET", "example.test:443", "", "", nil)
+	assert.Equal(t, int32(2), lookups.Load(), "a closed connection's processes are looked up again")
+}
@@ -0,0 +1,65 @@
+package proxy_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
+)
+
+func Test_recordsTraffic(t *testing.T) {
+	basecache := "testcache"
+	version := "1.1.1"
+
+	config := setup(t, basecache, version)
+	defer teardown(t, basecache)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = io.WriteString(w, "pong")
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	egress, err := proxy.NewEgressPolicy(proxy.EgressBlock, []string{serverURL.Host})
+	require.NoError(t, err)
+	wp, err := proxy.NewWrapperProxy(config, version, &debugLogger, caData)
+	require.NoError(t, err)
+	wp.SetUpstreamProxy(func(*http.Request) (*url.URL, error) { return nil, nil })
+	wp.SetEgressPolicy(egress)
+	require.NoError(t, wp.Start())
+	defer wp.Close()
+
+	proxiedClient, err := helper_getHttpClient(wp, true)
+	require.NoError(t, err)
+
+	res, err := proxiedClient.Post(server.URL+"/ping", "text/plain", strings.NewReader("ping!"))
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	res, err = proxiedClient.Get("http://collector.example.test/v1/events")
+	require.NoError(t, err)
+	res.Body.Close()
+
+	_, err = proxiedClient.Get("https://telemetry.example.test/v1/events")
+	assert.Error(t, err)
+
+	exchanges := wp.Traffic()
+	require.Len(t, exchanges, 3)
+	// The requests come from the test process, which is not started by the proxy's process
+	assert.Equal(t, proxy.Exchange{Method: http.MethodPost, Host: serverURL.Host, StatusCode: http.StatusOK, BytesSent: 5, BytesReceived: 4}, exchanges[0])
+	assert.Equal(t, http.MethodGet, exchanges[1].Method)
+	assert.Equal(t, "collector.example.test:80", exchanges[1].Host)
+	assert.Equal(t, http.StatusForbidden, exchanges[1].StatusCode)
+	assert.True(t, exchanges[1].Blocked)
+	assert.Equal(t, proxy.Exchange{Method: http.MethodConnect, Host: "telemetry.example.test:443", Blocked: true}, exchanges[2])
+}
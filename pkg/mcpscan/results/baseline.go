@@ -0,0 +1,112 @@
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// BaselineDiff describes how a scan differs from a previous run. New issues remain in
+// ScanResult.Issues; only they count towards the exit code.
+type BaselineDiff struct {
+	Changed   []ChangedIssue `json:"changed"`
+	Resolved  []Issue        `json:"resolved"`
+	Unchanged int            `json:"unchanged"`
+}
+
+// ChangedIssue is an issue reported at the same location as in the baseline but with a different
+// severity or message.
+type ChangedIssue struct {
+	Issue
+	Previous Issue `json:"previous"`
+}
+
+// LoadBaseline reads the JSON results of a previous run. Results of a run that was itself compared
+// to a baseline are refused, as they only list the issues that were new in that run.
+func LoadBaseline(path string) (*ScanResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline %s: %w", path, err)
+	}
+
+	var baseline ScanResult
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if baseline.Baseline != nil {
+		return nil, fmt.Errorf("baseline %s only contains the new issues of a run with --baseline, use the results of a run without it", path)
+	}
+	for _, issue := range baseline.knownIssues() {
+		if issue.Code == "" || issue.Severity.Rank() < 0 {
+			return nil, fmt.Errorf("baseline %s does not contain mcp-scan results", path)
+		}
+	}
+	baseline.UpdateSummary()
+
+	return &baseline, nil
+}
+
+// issueKey identifies an issue across runs by what was flagged and where.
+func issueKey(issue Issue) string {
+	return issue.Code + "\x00" + issue.ConfigPath + "\x00" + issue.ServerName + "\x00" + issue.ToolName
+}
+
+// knownIssues returns the reported issues followed by those suppressed by the policy file.
+func (r *ScanResult) knownIssues() []Issue {
+	issues := append([]Issue(nil), r.Issues...)
+	for _, suppressed := range r.Suppressed {
+		issues = append(issues, suppressed.Issue)
+	}
+	return issues
+}
+
+// ApplyBaseline reduces the result's issues to those not present in the baseline and records
+// changed and resolved issues in the result's baseline diff. Issues suppressed by the policy file
+// in either run count as known, so lifting a suppression does not turn an issue into a new one and
+// adding one does not resolve it.
+func (r *ScanResult) ApplyBaseline(baseline *ScanResult) {
+	known := baseline.knownIssues()
+	previous := map[string][]Issue{}
+	for _, issue := range known {
+		key := issueKey(issue)
+		previous[key] = append(previous[key], issue)
+	}
+	for _, suppressed := range r.Suppressed {
+		key := issueKey(suppressed.Issue)
+		if candidates := previous[key]; len(candidates) > 0 {
+			previous[key] = candidates[1:]
+		}
+	}
+
+	diff := &BaselineDiff{
+		Changed:  []ChangedIssue{},
+		Resolved: []Issue{},
+	}
+	newIssues := make([]Issue, 0, len(r.Issues))
+	for _, issue := range r.Issues {
+		key := issueKey(issue)
+		candidates := previous[key]
+		if len(candidates) == 0 {
+			newIssues = append(newIssues, issue)
+			continue
+		}
+		match := candidates[0]
+		previous[key] = candidates[1:]
+		if match.Severity != issue.Severity || match.Message != issue.Message {
+			diff.Changed = append(diff.Changed, ChangedIssue{Issue: issue, Previous: match})
+		} else {
+			diff.Unchanged++
+		}
+	}
+	for _, issue := range known {
+		key := issueKey(issue)
+		if len(previous[key]) > 0 {
+			diff.Resolved = append(diff.Resolved, previous[key]...)
+			previous[key] = nil
+		}
+	}
+
+	r.Issues = newIssues
+	r.Baseline = diff
+	r.UpdateSummary()
+}
@@ -0,0 +1,96 @@
+package results_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/results"
+)
+
+func TestApplyBaseline(t *testing.T) {
+	baseline := &results.ScanResult{Issues: []results.Issue{
+		{Code: "W001", Message: "Suspicious words", Severity: results.SeverityMedium, ConfigPath: "mcp.json", ServerName: "fs"},
+		{Code: "E001", Message: "Tool poisoning", Severity: results.SeverityMedium, ConfigPath: "mcp.json", ServerName: "fs", ToolName: "read_file"},
+		{Code: "E002", Message: "Cross origin", Severity: results.SeverityHigh, ConfigPath: "mcp.json", ServerName: "db"},
+	}}
+	current := &results.ScanResult{Issues: []results.Issue{
+		{Code: "W001", Message: "Suspicious words", Severity: results.SeverityMedium, ConfigPath: "mcp.json", ServerName: "fs"},
+		{Code: "E001", Message: "Tool poisoning", Severity: results.SeverityHigh, ConfigPath: "mcp.json", ServerName: "fs", ToolName: "read_file"},
+		{Code: "E001", Message: "Tool poisoning", Severity: results.SeverityHigh, ConfigPath: "mcp.json", ServerName: "fs", ToolName: "write_file"},
+	}}
+
+	current.ApplyBaseline(baseline)
+
+	require.Len(t, current.Issues, 1)
+	assert.Equal(t, "write_file", current.Issues[0].ToolName)
+	require.NotNil(t, current.Baseline)
+	require.Len(t, current.Baseline.Changed, 1)
+	assert.Equal(t, results.SeverityHigh, current.Baseline.Changed[0].Severity)
+	assert.Equal(t, results.SeverityMedium, current.Baseline.Changed[0].Previous.Severity)
+	require.Len(t, current.Baseline.Resolved, 1)
+	assert.Equal(t, "E002", current.Baseline.Resolved[0].Code)
+	assert.Equal(t, 1, current.Baseline.Unchanged)
+	assert.Equal(t, 1, current.Summary.Issues, "only new issues count")
+
+	out := results.RenderHuman(current)
+	assert.Contains(t, out, "New issues:")
+	assert.Contains(t, out, "previously [MEDIUM] Tool poisoning")
+	assert.Contains(t, out, "Compared to baseline: 1 new, 1 changed, 1 resolved, 1 unchanged")
+}
+
+func TestApplyBaseline_Suppressed(t *testing.T) {
+	issue := results.Issue{Code: "W001", Message: "Suspicious words", Severity: results.SeverityMedium, ConfigPath: "mcp.json", ServerName: "fs"}
+	other := results.Issue{Code: "E002", Message: "Cross origin", Severity: results.SeverityHigh, ConfigPath: "mcp.json", ServerName: "db"}
+
+	// The suppression of the issue was lifted since the baseline run
+	baseline := &results.ScanResult{Suppressed: []results.SuppressedIssue{{Issue: issue, Reason: "accepted"}}}
+	current := &results.ScanResult{Issues: []results.Issue{issue}}
+	current.ApplyBaseline(baseline)
+	assert.Empty(t, current.Issues)
+	assert.Equal(t, 1, current.Baseline.Unchanged)
+
+	// The issue was suppressed since the baseline run
+	baseline = &results.ScanResult{Issues: []results.Issue{issue, other}}
+	current = &results.ScanResult{Issues: []results.Issue{other}, Suppressed: []results.SuppressedIssue{{Issue: issue, Reason: "accepted"}}}
+	current.ApplyBaseline(baseline)
+	assert.Empty(t, current.Issues)
+	assert.Empty(t, current.Baseline.Resolved)
+	assert.Equal(t, 1, current.Baseline.Unchanged)
+}
+
+func TestLoadBaseline(t *testing.T) {
+	dir := t.TempDir()
+
+	previous, err := results.Parse([]byte(scannerOutput))
+	require.NoError(t, err)
+	data, err := json.Marshal(previous)
+	require.NoError(t, err)
+	path := filepath.Join(dir, "previous.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	baseline, err := results.LoadBaseline(path)
+	require.NoError(t, err)
+	assert.Len(t, baseline.Issues, 4)
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`{"issues": [{"foo": "bar"}]}`), 0o600))
+	_, err = results.LoadBaseline(invalid)
+	assert.Error(t, err)
+
+	_, err = results.LoadBaseline(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+
+	// The output of a run with --baseline only contains that run's new issues
+	previous.ApplyBaseline(baseline)
+	data, err = json.Marshal(previous)
+	require.NoError(t, err)
+	diffed := filepath.Join(dir, "diffed.json")
+	require.NoError(t, os.WriteFile(diffed, data, 0o600))
+	_, err = results.LoadBaseline(diffed)
+	assert.ErrorContains(t, err, "run without it")
+}
@@ -0,0 +1,135 @@
+package results
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderHuman renders a plain text report of the scan result for terminal output.
+func RenderHuman(r *ScanResult) string {
+	var b strings.Builder
+
+	for _, p := range r.Paths {
+		fmt.Fprintf(&b, "%s\n", p.Path)
+		if p.Error != "" {
+			fmt.Fprintf(&b, "  error: %s\n", p.Error)
+		}
+		for _, s := range p.Servers {
+			if s.Error != "" {
+				fmt.Fprintf(&b, "  x %s: %s\n", s.Name, s.Error)
+				continue
+			}
+			fmt.Fprintf(&b, "  - %s (%d tools)\n", s.Name, len(s.Tools))
+			writeNetwork(&b, "      ", s.Network)
+		}
+		b.WriteString("\n")
+	}
+
+	issuesTitle := "Issues"
+	if r.Baseline != nil {
+		issuesTitle = "New issues"
+	}
+	if len(r.Issues) > 0 {
+		fmt.Fprintf(&b, "%s:\n", issuesTitle)
+		for _, issue := range r.Issues {
+			writeIssue(&b, issue)
+		}
+		b.WriteString("\n")
+	}
+	if r.Baseline != nil && len(r.Baseline.Changed) > 0 {
+		b.WriteString("Changed issues:\n")
+		for _, changed := range r.Baseline.Changed {
+			writeIssue(&b, changed.Issue)
+			fmt.Fprintf(&b, "    previously [%s] %s\n", strings.ToUpper(string(changed.Previous.Severity)), changed.Previous.Message)
+		}
+		b.WriteString("\n")
+	}
+	if r.Baseline != nil && len(r.Baseline.Resolved) > 0 {
+		b.WriteString("Resolved issues:\n")
+		for _, issue := range r.Baseline.Resolved {
+			writeIssue(&b, issue)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.Network) > 0 {
+		if r.NetworkUnattributed {
+			b.WriteString("Network activity (requests cannot be attributed to servers on this platform):\n")
+		} else {
+			b.WriteString("Network activity of the scanner and unattributed processes:\n")
+		}
+		writeNetwork(&b, "  ", r.Network)
+		b.WriteString("\n")
+	}
+	if r.Egress != nil && len(r.Egress.Denied) > 0 {
+		if r.Egress.Mode == "block" {
+			b.WriteString("Blocked by the egress policy:\n")
+		} else {
+			b.WriteString("Outside the egress allowlist:\n")
+		}
+		for _, denied := range r.Egress.Denied {
+			fmt.Fprintf(&b, "  %s (%d attempt(s))\n", denied.Host, denied.Attempts)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "Scanned %d configuration(s), %d server(s), %d tool(s)\n", r.Summary.Paths, r.Summary.Servers, r.Summary.Tools)
+	if r.Baseline != nil {
+		fmt.Fprintf(&b, "Compared to baseline: %d new, %d changed, %d resolved, %d unchanged\n",
+			r.Summary.Issues, len(r.Baseline.Changed), len(r.Baseline.Resolved), r.Baseline.Unchanged)
+	}
+	if r.Summary.Issues == 0 {
+		b.WriteString("No issues found\n")
+	} else {
+		counts := make([]string, 0, len(Severities))
+		for i := len(Severities) - 1; i >= 0; i-- {
+			counts = append(counts, fmt.Sprintf("%d %s", r.Summary.BySeverity[Severities[i]], Severities[i]))
+		}
+		fmt.Fprintf(&b, "%d issue(s) found: %s\n", r.Summary.Issues, strings.Join(counts, ", "))
+	}
+	if r.Summary.Suppressed > 0 {
+		fmt.Fprintf(&b, "%d issue(s) suppressed by policy\n", r.Summary.Suppressed)
+	}
+
+	return b.String()
+}
+
+func writeIssue(b *strings.Builder, issue Issue) {
+	fmt.Fprintf(b, "  [%s] %s %s: %s\n", strings.ToUpper(string(issue.Severity)), issue.Code, issueLocation(issue), issue.Message)
+}
+
+func issueLocation(issue Issue) string {
+	location := issue.ConfigPath
+	if issue.ServerName != "" {
+		location = issue.ServerName
+		if issue.ToolName != "" {
+			location += "/" + issue.ToolName
+		}
+	}
+	return location
+}
+
+func writeNetwork(b *strings.Builder, indent string, activities []NetworkActivity) {
+	for _, activity := range activities {
+		fmt.Fprintf(b, "%snetwork: %s %s (%d request(s)", indent, strings.Join(activity.Methods, ","), activity.Host, activity.Requests)
+		if activity.Blocked > 0 {
+			fmt.Fprintf(b, ", %d blocked", activity.Blocked)
+		}
+		fmt.Fprintf(b, ", %s sent, %s received)\n", formatBytes(activity.BytesSent), formatBytes(activity.BytesReceived))
+	}
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffix := float64(n)/unit, "KiB"
+	for _, next := range []string{"MiB", "GiB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
@@ -0,0 +1,347 @@
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// Severities lists all known severities ordered from lowest to highest.
+var Severities = []Severity{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// Rank returns the position of the severity in Severities, or -1 for unknown values.
+func (s Severity) Rank() int {
+	for i, known := range Severities {
+		if s == known {
+			return i
+		}
+	}
+	return -1
+}
+
+func ParseSeverity(value string) (Severity, error) {
+	s := Severity(strings.ToLower(strings.TrimSpace(value)))
+	if s.Rank() < 0 {
+		return "", fmt.Errorf("unknown severity %q, expected one of low, medium, high, critical", value)
+	}
+	return s, nil
+}
+
+// ScanResult is the typed representation of a scanner run, returned as workflow data.
+type ScanResult struct {
+	Paths      []PathResult      `json:"paths"`
+	Issues     []Issue           `json:"issues"`
+	Suppressed []SuppressedIssue `json:"suppressed"`
+	Baseline   *BaselineDiff     `json:"baseline,omitempty"`
+	Egress     *EgressReport     `json:"egress,omitempty"`
+	// Network is the network activity of the scanner itself and of processes that could not be
+	// attributed to a server.
+	Network []NetworkActivity `json:"network,omitempty"`
+	// NetworkUnattributed is set when the platform cannot tell which process sent a request, so
+	// the network activity of stdio servers is part of Network as well.
+	NetworkUnattributed bool    `json:"networkUnattributed,omitempty"`
+	Summary             Summary `json:"summary"`
+}
+
+// EgressReport lists the destinations outside the egress allowlist that were requested during the
+// scan. In block mode they were rejected, in warn mode they were let through.
+type EgressReport struct {
+	Mode   string       `json:"mode"`
+	Denied []DeniedHost `json:"denied"`
+}
+
+type DeniedHost struct {
+	Host     string `json:"host"`
+	Attempts int    `json:"attempts"`
+}
+
+type PathResult struct {
+	Path    string         `json:"path"`
+	Client  string         `json:"client,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Servers []ServerResult `json:"servers"`
+}
+
+type ServerResult struct {
+	Name      string            `json:"name"`
+	Type      string            `json:"type,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Tools     []Entity          `json:"tools"`
+	Prompts   []Entity          `json:"prompts,omitempty"`
+	Resources []Entity          `json:"resources,omitempty"`
+	Network   []NetworkActivity `json:"network,omitempty"`
+
+	// Command, Args and URL describe how the scanner launched or connected to the server. They are
+	// used to attribute network activity and are not part of the output, as they may hold secrets.
+	Command string   `json:"-"`
+	Args    []string `json:"-"`
+	URL     string   `json:"-"`
+}
+
+// NetworkActivity summarizes the requests to a single host:port seen by the proxy during the scan.
+type NetworkActivity struct {
+	Host          string   `json:"host"`
+	Methods       []string `json:"methods"`
+	Requests      int      `json:"requests"`
+	Blocked       int      `json:"blocked,omitempty"`
+	BytesSent     int64    `json:"bytesSent"`
+	BytesReceived int64    `json:"bytesReceived"`
+}
+
+type Entity struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type Issue struct {
+	Code       string   `json:"code"`
+	Message    string   `json:"message"`
+	Severity   Severity `json:"severity"`
+	ConfigPath string   `json:"configPath"`
+	ServerName string   `json:"serverName,omitempty"`
+	ToolName   string   `json:"toolName,omitempty"`
+}
+
+// SuppressedIssue is an issue ignored by the policy file, along with the rule's justification.
+type SuppressedIssue struct {
+	Issue
+	Reason  string `json:"reason"`
+	Expires string `json:"expires"`
+}
+
+type Summary struct {
+	Paths      int              `json:"paths"`
+	Servers    int              `json:"servers"`
+	Tools      int              `json:"tools"`
+	Issues     int              `json:"issues"`
+	Suppressed int              `json:"suppressed"`
+	BySeverity map[Severity]int `json:"bySeverity"`
+}
+
+// UpdateSummary recomputes the summary from the current paths and issues.
+func (r *ScanResult) UpdateSummary() {
+	summary := Summary{
+		Paths:      len(r.Paths),
+		Issues:     len(r.Issues),
+		Suppressed: len(r.Suppressed),
+		BySeverity: make(map[Severity]int, len(Severities)),
+	}
+	for _, s := range Severities {
+		summary.BySeverity[s] = 0
+	}
+	for _, p := range r.Paths {
+		summary.Servers += len(p.Servers)
+		for _, s := range p.Servers {
+			summary.Tools += len(s.Tools)
+		}
+	}
+	for _, issue := range r.Issues {
+		summary.BySeverity[issue.Severity]++
+	}
+	r.Summary = summary
+}
+
+// FilterBySeverity drops issues below the given severity and refreshes the summary.
+func (r *ScanResult) FilterBySeverity(threshold Severity) {
+	filtered := make([]Issue, 0, len(r.Issues))
+	for _, issue := range r.Issues {
+		if issue.Severity.Rank() >= threshold.Rank() {
+			filtered = append(filtered, issue)
+		}
+	}
+	r.Issues = filtered
+	r.UpdateSummary()
+}
+
+// HasScanTargets reports whether the scanner found at least one MCP server to scan.
+func (r *ScanResult) HasScanTargets() bool {
+	return r.Summary.Servers > 0
+}
+
+// raw* types mirror the JSON emitted by `mcp-scan scan --json`.
+type rawScanError struct {
+	Message string `json:"message"`
+}
+
+type rawEntity struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type rawSignature struct {
+	Prompts           []rawEntity `json:"prompts"`
+	Resources         []rawEntity `json:"resources"`
+	ResourceTemplates []rawEntity `json:"resource_templates"`
+	Tools             []rawEntity `json:"tools"`
+}
+
+type rawServerResult struct {
+	Name   string `json:"name"`
+	Server struct {
+		Type    string   `json:"type"`
+		Command string   `json:"command"`
+		Args    []string `json:"args"`
+		URL     string   `json:"url"`
+	} `json:"server"`
+	Signature *rawSignature `json:"signature"`
+	Error     *rawScanError `json:"error"`
+}
+
+type rawIssue struct {
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	Reference []*int         `json:"reference"`
+	ExtraData map[string]any `json:"extra_data"`
+}
+
+type rawPathResult struct {
+	Client  string            `json:"client"`
+	Path    string            `json:"path"`
+	Servers []rawServerResult `json:"servers"`
+	Issues  []rawIssue        `json:"issues"`
+	Error   *rawScanError     `json:"error"`
+}
+
+// Parse converts the scanner's JSON output into a ScanResult.
+// The scanner emits an object keyed by config path; a plain list of path results is accepted as well.
+func Parse(data []byte) (*ScanResult, error) {
+	var rawPaths []rawPathResult
+
+	var byPath map[string]rawPathResult
+	if err := json.Unmarshal(data, &byPath); err == nil {
+		keys := make([]string, 0, len(byPath))
+		for k := range byPath {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := byPath[k]
+			if p.Path == "" {
+				p.Path = k
+			}
+			rawPaths = append(rawPaths, p)
+		}
+	} else if listErr := json.Unmarshal(data, &rawPaths); listErr != nil {
+		return nil, fmt.Errorf("failed to parse mcp-scan output: %w", err)
+	}
+
+	result := &ScanResult{
+		Paths:      make([]PathResult, 0, len(rawPaths)),
+		Issues:     []Issue{},
+		Suppressed: []SuppressedIssue{},
+	}
+	for _, rp := range rawPaths {
+		path := PathResult{
+			Path:    rp.Path,
+			Client:  rp.Client,
+			Error:   errorMessage(rp.Error),
+			Servers: make([]ServerResult, 0, len(rp.Servers)),
+		}
+		for _, rs := range rp.Servers {
+			path.Servers = append(path.Servers, convertServer(rs))
+		}
+		for _, ri := range rp.Issues {
+			result.Issues = append(result.Issues, convertIssue(rp, ri))
+		}
+		result.Paths = append(result.Paths, path)
+	}
+	result.UpdateSummary()
+
+	return result, nil
+}
+
+func errorMessage(e *rawScanError) string {
+	if e == nil {
+		return ""
+	}
+	return e.Message
+}
+
+func convertEntities(raw []rawEntity) []Entity {
+	out := make([]Entity, 0, len(raw))
+	for _, e := range raw {
+		out = append(out, Entity(e))
+	}
+	return out
+}
+
+func convertServer(rs rawServerResult) ServerResult {
+	s := ServerResult{
+		Name:    rs.Name,
+		Type:    rs.Server.Type,
+		Error:   errorMessage(rs.Error),
+		Tools:   []Entity{},
+		Command: rs.Server.Command,
+		Args:    rs.Server.Args,
+		URL:     rs.Server.URL,
+	}
+	if rs.Signature != nil {
+		s.Tools = convertEntities(rs.Signature.Tools)
+		s.Prompts = convertEntities(rs.Signature.Prompts)
+		s.Resources = convertEntities(append(append([]rawEntity{}, rs.Signature.Resources...), rs.Signature.ResourceTemplates...))
+	}
+	return s
+}
+
+// entityName resolves an issue reference to an entity name. The scanner indexes entities
+// as prompts, resources, resource templates and tools concatenated in that order.
+func entityName(sig *rawSignature, index int) string {
+	if sig == nil || index < 0 {
+		return ""
+	}
+	for _, group := range [][]rawEntity{sig.Prompts, sig.Resources, sig.ResourceTemplates, sig.Tools} {
+		if index < len(group) {
+			return group[index].Name
+		}
+		index -= len(group)
+	}
+	return ""
+}
+
+func convertIssue(rp rawPathResult, ri rawIssue) Issue {
+	issue := Issue{
+		Code:       ri.Code,
+		Message:    ri.Message,
+		Severity:   issueSeverity(ri),
+		ConfigPath: rp.Path,
+	}
+	if len(ri.Reference) > 0 && ri.Reference[0] != nil {
+		serverIndex := *ri.Reference[0]
+		if serverIndex >= 0 && serverIndex < len(rp.Servers) {
+			server := rp.Servers[serverIndex]
+			issue.ServerName = server.Name
+			if len(ri.Reference) > 1 && ri.Reference[1] != nil {
+				issue.ToolName = entityName(server.Signature, *ri.Reference[1])
+			}
+		}
+	}
+	return issue
+}
+
+// issueSeverity prefers an explicit severity reported by the scanner and otherwise
+// derives one from the issue code prefix (E: error, TF: toxic flow, W: warning).
+func issueSeverity(ri rawIssue) Severity {
+	if value, ok := ri.ExtraData["severity"].(string); ok {
+		if s, err := ParseSeverity(value); err == nil {
+			return s
+		}
+	}
+	switch {
+	case strings.HasPrefix(ri.Code, "TF"), strings.HasPrefix(ri.Code, "E"):
+		return SeverityHigh
+	case strings.HasPrefix(ri.Code, "W"):
+		return SeverityMedium
+	default:
+		return SeverityLow
+	}
+}
@@ -0,0 +1,153 @@
+package results_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/results"
+)
+
+const scannerOutput = `{
+  "/home/dev/.cursor/mcp.json": {
+    "client": "cursor",
+    "path": "/home/dev/.cursor/mcp.json",
+    "servers": [
+      {
+        "name": "filesystem",
+        "server": {"type": "stdio", "command": "npx"},
+        "signature": {
+          "prompts": [{"name": "summarize"}],
+          "resources": [],
+          "tools": [
+            {"name": "read_file", "description": "Read a file"},
+            {"name": "write_file", "description": "Write a file"}
+          ]
+        },
+        "error": null
+      },
+      {
+        "name": "broken",
+        "server": {"type": "sse"},
+        "signature": null,
+        "error": {"message": "could not start server"}
+      }
+    ],
+    "issues": [
+      {"code": "E001", "message": "Tool poisoning detected", "reference": [0, 2]},
+      {"code": "W001", "message": "Suspicious words", "reference": [0, null]},
+      {"code": "TF001", "message": "Toxic flow", "reference": null, "extra_data": {"severity": "critical"}},
+      {"code": "X002", "message": "Unknown", "reference": [7, 0]}
+    ],
+    "error": null
+  },
+  "/home/dev/.vscode/mcp.json": {
+    "path": "/home/dev/.vscode/mcp.json",
+    "servers": [],
+    "issues": [],
+    "error": {"message": "file not found"}
+  }
+}`
+
+func TestParse(t *testing.T) {
+	result, err := results.Parse([]byte(scannerOutput))
+	require.NoError(t, err)
+
+	require.Len(t, result.Paths, 2)
+	cursor := result.Paths[0]
+	assert.Equal(t, "/home/dev/.cursor/mcp.json", cursor.Path)
+	assert.Equal(t, "cursor", cursor.Client)
+	require.Len(t, cursor.Servers, 2)
+	assert.Equal(t, "filesystem", cursor.Servers[0].Name)
+	assert.Equal(t, "stdio", cursor.Servers[0].Type)
+	assert.Len(t, cursor.Servers[0].Tools, 2)
+	assert.Equal(t, "could not start server", cursor.Servers[1].Error)
+	assert.Equal(t, "file not found", result.Paths[1].Error)
+
+	require.Len(t, result.Issues, 4)
+	assert.Equal(t, results.Issue{
+		Code:       "E001",
+		Message:    "Tool poisoning detected",
+		Severity:   results.SeverityHigh,
+		ConfigPath: "/home/dev/.cursor/mcp.json",
+		ServerName: "filesystem",
+		ToolName:   "write_file",
+	}, result.Issues[0])
+	assert.Equal(t, "filesystem", result.Issues[1].ServerName)
+	assert.Empty(t, result.Issues[1].ToolName)
+	assert.Equal(t, results.SeverityMedium, result.Issues[1].Severity)
+	assert.Equal(t, results.SeverityCritical, result.Issues[2].Severity)
+	assert.Empty(t, result.Issues[3].ServerName, "out of range references are ignored")
+	assert.Equal(t, results.SeverityLow, result.Issues[3].Severity)
+
+	assert.Equal(t, 2, result.Summary.Paths)
+	assert.Equal(t, 2, result.Summary.Servers)
+	assert.Equal(t, 2, result.Summary.Tools)
+	assert.Equal(t, 4, result.Summary.Issues)
+	assert.Equal(t, 1, result.Summary.BySeverity[results.SeverityCritical])
+	assert.Equal(t, 1, result.Summary.BySeverity[results.SeverityHigh])
+}
+
+func TestParse_List(t *testing.T) {
+	result, err := results.Parse([]byte(`[{"path": "a.json", "servers": [], "issues": [{"code": "E001", "message": "m"}]}]`))
+	require.NoError(t, err)
+	require.Len(t, result.Paths, 1)
+	assert.Equal(t, "a.json", result.Issues[0].ConfigPath)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := results.Parse([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestParseSeverity(t *testing.T) {
+	s, err := results.ParseSeverity(" HIGH ")
+	require.NoError(t, err)
+	assert.Equal(t, results.SeverityHigh, s)
+
+	_, err = results.ParseSeverity("urgent")
+	assert.Error(t, err)
+
+	assert.Greater(t, results.SeverityCritical.Rank(), results.SeverityLow.Rank())
+}
+
+func TestRenderHuman(t *testing.T) {
+	result, err := results.Parse([]byte(scannerOutput))
+	require.NoError(t, err)
+
+	out := results.RenderHuman(result)
+	assert.Contains(t, out, "- filesystem (2 tools)")
+	assert.Contains(t, out, "x broken: could not start server")
+	assert.Contains(t, out, "[HIGH] E001 filesystem/write_file: Tool poisoning detected")
+	assert.Contains(t, out, "4 issue(s) found: 1 critical, 1 high, 1 medium, 1 low")
+
+	empty, err := results.Parse([]byte(`{}`))
+	require.NoError(t, err)
+	assert.Contains(t, results.RenderHuman(empty), "No issues found")
+	assert.NotContains(t, results.RenderHuman(empty), "egress")
+
+	result.Paths[0].Servers[0].Network = []results.NetworkActivity{{Host: "registry.npmjs.org:443", Methods: []string{"GET"}, Requests: 2, BytesSent: 512, BytesReceived: 3 << 20}}
+	result.Network = []results.NetworkActivity{{Host: "api.snyk.io:443", Methods: []string{"POST"}, Requests: 1, Blocked: 1, BytesSent: 2048}}
+	out = results.RenderHuman(result)
+	assert.Contains(t, out, "- filesystem (2 tools)\n      network: GET registry.npmjs.org:443 (2 request(s), 512 B sent, 3.0 MiB received)\n")
+	assert.Contains(t, out, "Network activity of the scanner and unattributed processes:\n  network: POST api.snyk.io:443 (1 request(s), 1 blocked, 2.0 KiB sent, 0 B received)\n")
+	result.NetworkUnattributed = true
+	assert.Contains(t, results.RenderHuman(result), "Network activity (requests cannot be attributed to servers on this platform):\n  network: POST api.snyk.io:443")
+
+	empty.Egress = &results.EgressReport{Mode: "block", Denied: []results.DeniedHost{{Host: "collector.example.com:443", Attempts: 3}}}
+	assert.Contains(t, results.RenderHuman(empty), "Blocked by the egress policy:\n  collector.example.com:443 (3 attempt(s))")
+}
+
+func TestFilterBySeverity(t *testing.T) {
+	result, err := results.Parse([]byte(scannerOutput))
+	require.NoError(t, err)
+	require.True(t, result.HasScanTargets())
+
+	result.FilterBySeverity(results.SeverityHigh)
+	require.Len(t, result.Issues, 2)
+	assert.Equal(t, "E001", result.Issues[0].Code)
+	assert.Equal(t, "TF001", result.Issues[1].Code)
+	assert.Equal(t, 2, result.Summary.Issues)
+	assert.Equal(t, 0, result.Summary.BySeverity[results.SeverityMedium])
+}
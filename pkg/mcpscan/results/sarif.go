@@ -0,0 +1,200 @@
+package results
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sarifSchema  = "https://docs.oasis-open.org/sarif/sarif/v2.1.0/errata01/os/schemas/sarif-schema-2.1.0.json"
+	sarifVersion = "2.1.0"
+	sarifTool    = "Snyk MCP Scan"
+	sarifToolURI = "https://docs.snyk.io"
+)
+
+type SarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+type SarifRun struct {
+	Tool    SarifTool     `json:"tool"`
+	Results []SarifResult `json:"results"`
+}
+
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+type SarifDriver struct {
+	Name            string      `json:"name"`
+	SemanticVersion string      `json:"semanticVersion,omitempty"`
+	InformationURI  string      `json:"informationUri"`
+	Rules           []SarifRule `json:"rules"`
+}
+
+type SarifRule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     SarifMessage           `json:"shortDescription"`
+	DefaultConfiguration SarifRuleConfiguration `json:"defaultConfiguration"`
+	Properties           SarifRuleProperties    `json:"properties"`
+}
+
+type SarifRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type SarifRuleProperties struct {
+	SecuritySeverity string   `json:"security-severity"`
+	Tags             []string `json:"tags"`
+}
+
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+type SarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   SarifMessage    `json:"message"`
+	Locations []SarifLocation `json:"locations"`
+}
+
+type SarifLocation struct {
+	PhysicalLocation SarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []SarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+}
+
+type SarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type SarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityCritical, SeverityHigh:
+		return "error"
+	case SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifSecuritySeverity maps severities onto the numeric scale used by code scanning tools.
+func sarifSecuritySeverity(s Severity) string {
+	switch s {
+	case SeverityCritical:
+		return "9.0"
+	case SeverityHigh:
+		return "7.5"
+	case SeverityMedium:
+		return "5.0"
+	default:
+		return "2.0"
+	}
+}
+
+// ToSarif converts the scan result into a SARIF 2.1.0 log with one rule per issue code.
+func ToSarif(r *ScanResult, toolVersion string) *SarifLog {
+	run := SarifRun{
+		Tool: SarifTool{Driver: SarifDriver{
+			Name:            sarifTool,
+			SemanticVersion: toolVersion,
+			InformationURI:  sarifToolURI,
+			Rules:           []SarifRule{},
+		}},
+		Results: []SarifResult{},
+	}
+
+	ruleIndex := map[string]int{}
+	ruleSeverity := map[string]Severity{}
+	for _, issue := range r.Issues {
+		index, ok := ruleIndex[issue.Code]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[issue.Code] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, SarifRule{
+				ID:               issue.Code,
+				ShortDescription: SarifMessage{Text: issue.Message},
+				Properties:       SarifRuleProperties{Tags: []string{"security", "mcp"}},
+			})
+		}
+		// a rule reports the highest severity seen for its code
+		if issue.Severity.Rank() > ruleSeverity[issue.Code].Rank() {
+			ruleSeverity[issue.Code] = issue.Severity
+			rule := &run.Tool.Driver.Rules[index]
+			rule.DefaultConfiguration.Level = sarifLevel(issue.Severity)
+			rule.Properties.SecuritySeverity = sarifSecuritySeverity(issue.Severity)
+		}
+
+		run.Results = append(run.Results, SarifResult{
+			RuleID:    issue.Code,
+			RuleIndex: index,
+			Level:     sarifLevel(issue.Severity),
+			Message:   SarifMessage{Text: issue.Message},
+			Locations: []SarifLocation{sarifLocation(issue)},
+		})
+	}
+
+	return &SarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []SarifRun{run},
+	}
+}
+
+// sarifArtifactURI turns a configuration path into a file URI, or a relative URI reference for a
+// relative path. The scanner reports paths in the home directory with a leading ~.
+func sarifArtifactURI(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~"); ok && (rest == "" || os.IsPathSeparator(rest[0])) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	if !filepath.IsAbs(path) {
+		return (&url.URL{Path: filepath.ToSlash(path)}).String()
+	}
+	path = filepath.ToSlash(path)
+	if filepath.VolumeName(path) != "" {
+		// Windows drive letters become file:///C:/...
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+func sarifLocation(issue Issue) SarifLocation {
+	location := SarifLocation{
+		PhysicalLocation: SarifPhysicalLocation{
+			ArtifactLocation: SarifArtifactLocation{URI: sarifArtifactURI(issue.ConfigPath)},
+		},
+	}
+	if issue.ServerName != "" {
+		location.LogicalLocations = append(location.LogicalLocations, SarifLogicalLocation{
+			Name:               issue.ServerName,
+			FullyQualifiedName: issue.ServerName,
+			Kind:               "module",
+		})
+		if issue.ToolName != "" {
+			location.LogicalLocations = append(location.LogicalLocations, SarifLogicalLocation{
+				Name:               issue.ToolName,
+				FullyQualifiedName: issue.ServerName + "/" + issue.ToolName,
+				Kind:               "function",
+			})
+		}
+	}
+	return location
+}
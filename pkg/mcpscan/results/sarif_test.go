@@ -0,0 +1,87 @@
+package results_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/results"
+)
+
+func TestToSarif(t *testing.T) {
+	result := &results.ScanResult{
+		Issues: []results.Issue{
+			{Code: "W001", Message: "Suspicious words", Severity: results.SeverityMedium, ConfigPath: "/home/dev/.cursor/mcp.json", ServerName: "fs"},
+			{Code: "E001", Message: "Tool poisoning", Severity: results.SeverityHigh, ConfigPath: "/home/dev/.cursor/mcp.json", ServerName: "fs", ToolName: "read_file"},
+			{Code: "W001", Message: "Suspicious words", Severity: results.SeverityCritical, ConfigPath: "/home/dev/.vscode/mcp.json"},
+		},
+	}
+
+	log := results.ToSarif(result, "0.4.2")
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "0.4.2", run.Tool.Driver.SemanticVersion)
+
+	require.Len(t, run.Tool.Driver.Rules, 2, "one rule per issue code")
+	assert.Equal(t, "W001", run.Tool.Driver.Rules[0].ID)
+	assert.Equal(t, "error", run.Tool.Driver.Rules[0].DefaultConfiguration.Level, "rule uses the highest severity of its code")
+	assert.Equal(t, "9.0", run.Tool.Driver.Rules[0].Properties.SecuritySeverity)
+	assert.Equal(t, "E001", run.Tool.Driver.Rules[1].ID)
+
+	require.Len(t, run.Results, 3)
+	assert.Equal(t, 0, run.Results[0].RuleIndex)
+	assert.Equal(t, "warning", run.Results[0].Level)
+	assert.Equal(t, 1, run.Results[1].RuleIndex)
+
+	location := run.Results[1].Locations[0]
+	assert.Equal(t, "file:///home/dev/.cursor/mcp.json", location.PhysicalLocation.ArtifactLocation.URI)
+	require.Len(t, location.LogicalLocations, 2)
+	assert.Equal(t, "fs", location.LogicalLocations[0].Name)
+	assert.Equal(t, "fs/read_file", location.LogicalLocations[1].FullyQualifiedName)
+	assert.Empty(t, run.Results[2].Locations[0].LogicalLocations)
+
+	payload, err := json.Marshal(log)
+	require.NoError(t, err)
+	assert.Contains(t, string(payload), `"$schema"`)
+}
+
+func TestToSarif_NoIssues(t *testing.T) {
+	log := results.ToSarif(&results.ScanResult{}, "0.4.2")
+	payload, err := json.Marshal(log)
+	require.NoError(t, err)
+	assert.Contains(t, string(payload), `"results":[]`)
+	assert.Contains(t, string(payload), `"rules":[]`)
+}
+
+func TestToSarif_ArtifactURI(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	tests := []struct {
+		path     string
+		expected string
+		goos     string
+	}{
+		{path: "mcp.json", expected: "mcp.json"},
+		{path: "my configs/mcp.json", expected: "my%20configs/mcp.json"},
+		{path: "~/.cursor/mcp.json", expected: (&url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(home, ".cursor", "mcp.json"))}).String()},
+		{path: "/home/dev/my configs/mcp.json", expected: "file:///home/dev/my%20configs/mcp.json", goos: "linux"},
+		{path: `C:\Users\dev\.cursor\mcp.json`, expected: "file:///C:/Users/dev/.cursor/mcp.json", goos: "windows"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if tt.goos != "" && tt.goos != runtime.GOOS {
+				t.Skipf("%s paths are only absolute on %s", tt.goos, tt.goos)
+			}
+			log := results.ToSarif(&results.ScanResult{Issues: []results.Issue{{Code: "W001", ConfigPath: tt.path}}}, "0.4.2")
+			assert.Equal(t, tt.expected, log.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snyk/go-application-framework/pkg/workflow"
+)
+
+// partialDownloadSuffix names the file a download is written to until it has been verified.
+// It is kept across runs so an interrupted download can be resumed.
+const partialDownloadSuffix = ".download-partial"
+
+const maxDownloadAttempts = 5
+
+// downloadRetryDelay is the wait before the first retry; it doubles with every further attempt.
+var downloadRetryDelay = time.Second
+
+const maxDownloadRetryDelay = 30 * time.Second
+
+// permanentDownloadError marks failures retrying cannot fix, such as a missing asset.
+type permanentDownloadError struct {
+	err error
+}
+
+func (e *permanentDownloadError) Error() string { return e.err.Error() }
+
+func (e *permanentDownloadError) Unwrap() error { return e.err }
+
+// progressReader reports the bytes read from a download body.
+type progressReader struct {
+	reader     io.Reader
+	done       int64
+	total      int64
+	onProgress func(done, total int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.done += int64(n)
+	if n > 0 && r.onProgress != nil {
+		r.onProgress(r.done, r.total)
+	}
+	return n, err
+}
+
+// downloadFile downloads url to dest, retrying transient failures with exponential backoff.
+// Bytes already present in dest, e.g. from an interrupted run, are resumed with a Range request
+// when the server supports it. onProgress receives the bytes written so far and the expected
+// size, which is -1 if the server does not announce it.
+func downloadFile(ctx workflow.InvocationContext, url string, headers http.Header, dest string, onProgress func(done, total int64)) error {
+	logger := ctx.GetEnhancedLogger()
+	delay := downloadRetryDelay
+	var err error
+	for attempt := 1; attempt <= maxDownloadAttempts; attempt++ {
+		err = downloadAttempt(ctx, url, headers, dest, onProgress)
+		var permanent *permanentDownloadError
+		if err == nil || errors.As(err, &permanent) {
+			return err
+		}
+		if attempt == maxDownloadAttempts {
+			break
+		}
+		logger.Debug().Err(err).Int("attempt", attempt).Dur("retryIn", delay).Msg("Download of mcp-scan binary failed, retrying")
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Context().Done():
+			timer.Stop()
+			return fmt.Errorf("download interrupted after %d attempts: %w", attempt, ctx.Context().Err())
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxDownloadRetryDelay)
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", maxDownloadAttempts, err)
+}
+
+func downloadAttempt(ctx workflow.InvocationContext, url string, headers http.Header, dest string, onProgress func(done, total int64)) error {
+	var offset int64
+	if info, err := os.Stat(dest); err == nil {
+		offset = info.Size()
+	}
+
+	requestHeaders := headers.Clone()
+	if requestHeaders == nil {
+		requestHeaders = http.Header{}
+	}
+	if offset > 0 {
+		requestHeaders.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := httpGet(ctx, url, requestHeaders)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && contentRangeStart(resp.Header.Get("Content-Range")) == offset:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		offset = 0
+		flags |= os.O_TRUNC
+	case resp.StatusCode == http.StatusPartialContent || resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file does not line up with what the server has, start over.
+		_ = os.Remove(dest)
+		return fmt.Errorf("cannot resume download: unexpected status %s", resp.Status)
+	case isRetryableStatus(resp.StatusCode):
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	default:
+		return &permanentDownloadError{err: fmt.Errorf("unexpected status %s", resp.Status)}
+	}
+
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	f, err := os.OpenFile(dest, flags, 0o600)
+	if err != nil {
+		return &permanentDownloadError{err: fmt.Errorf("failed to open download file: %w", err)}
+	}
+	_, copyErr := io.Copy(f, &progressReader{reader: resp.Body, done: offset, total: total, onProgress: onProgress})
+	closeErr := f.Close()
+	if copyErr != nil {
+		return fmt.Errorf("failed to write downloaded binary: %w", copyErr)
+	}
+	if closeErr != nil {
+		return &permanentDownloadError{err: fmt.Errorf("failed to close downloaded binary: %w", closeErr)}
+	}
+	return nil
+}
+
+// contentRangeStart returns the first byte position of a "bytes start-end/size" header, or -1.
+func contentRangeStart(header string) int64 {
+	rangeSpec, found := strings.CutPrefix(header, "bytes ")
+	if !found {
+		return -1
+	}
+	start, _, found := strings.Cut(rangeSpec, "-")
+	if !found {
+		return -1
+	}
+	n, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
@@ -0,0 +1,233 @@
+package runner //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testBinaryContent = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+func withFastRetries(t *testing.T) {
+	t.Helper()
+	previous := downloadRetryDelay
+	downloadRetryDelay = time.Millisecond
+	t.Cleanup(func() { downloadRetryDelay = previous })
+}
+
+// rangeServer serves testBinaryContent and honours "bytes=N-" range requests.
+func rangeServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request) bool) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if handler != nil && handler(w, r) {
+			return
+		}
+		var start int
+		if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+			if _, err := fmt.Sscanf(rangeHeader, "bytes=%d-", &start); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(testBinaryContent)-1, len(testBinaryContent)))
+			w.Header().Set("Content-Length", fmt.Sprint(len(testBinaryContent)-start))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", fmt.Sprint(len(testBinaryContent)))
+		}
+		_, _ = io.WriteString(w, testBinaryContent[start:])
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestDownloadFile_RetriesTransientFailures(t *testing.T) {
+	withFastRetries(t)
+	var requests atomic.Int32
+	server := rangeServer(t, func(w http.ResponseWriter, _ *http.Request) bool {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return true
+		}
+		return false
+	})
+
+	dest := filepath.Join(t.TempDir(), "mcp-scan"+partialDownloadSuffix)
+	var lastDone, lastTotal int64
+	err := downloadFile(newDownloadTestContext(t, t.TempDir()), server.URL, nil, dest, func(done, total int64) {
+		lastDone, lastTotal = done, total
+	})
+	if err != nil {
+		t.Fatalf("expected download to succeed after retries: %v", err)
+	}
+	if requests.Load() != 3 {
+		t.Fatalf("expected 3 requests, got %d", requests.Load())
+	}
+	if got := readFile(t, dest); got != testBinaryContent {
+		t.Fatalf("unexpected content %q", got)
+	}
+	if lastDone != int64(len(testBinaryContent)) || lastTotal != int64(len(testBinaryContent)) {
+		t.Fatalf("expected progress to reach %d/%d, got %d/%d", len(testBinaryContent), len(testBinaryContent), lastDone, lastTotal)
+	}
+}
+
+func TestDownloadFile_ResumesPartialDownload(t *testing.T) {
+	withFastRetries(t)
+	var ranges []string
+	server := rangeServer(t, func(_ http.ResponseWriter, r *http.Request) bool {
+		ranges = append(ranges, r.Header.Get("Range"))
+		return false
+	})
+
+	dest := filepath.Join(t.TempDir(), "mcp-scan"+partialDownloadSuffix)
+	if err := os.WriteFile(dest, []byte(testBinaryContent[:10]), 0o600); err != nil {
+		t.Fatalf("failed to write partial download: %v", err)
+	}
+
+	var firstDone int64 = -1
+	err := downloadFile(newDownloadTestContext(t, t.TempDir()), server.URL, nil, dest, func(done, _ int64) {
+		if firstDone < 0 {
+			firstDone = done
+		}
+	})
+	if err != nil {
+		t.Fatalf("expected resumed download to succeed: %v", err)
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=10-" {
+		t.Fatalf("expected a single range request from byte 10, got %v", ranges)
+	}
+	if got := readFile(t, dest); got != testBinaryContent {
+		t.Fatalf("unexpected content %q", got)
+	}
+	if firstDone <= 10 {
+		t.Fatalf("expected progress to include resumed bytes, got %d", firstDone)
+	}
+}
+
+func TestDownloadFile_ResumesAfterInterruptedTransfer(t *testing.T) {
+	withFastRetries(t)
+	var requests atomic.Int32
+	server := rangeServer(t, func(w http.ResponseWriter, _ *http.Request) bool {
+		if requests.Add(1) > 1 {
+			return false
+		}
+		// Announce the full size but drop the connection half way through.
+		w.Header().Set("Content-Length", fmt.Sprint(len(testBinaryContent)))
+		_, _ = io.WriteString(w, testBinaryContent[:20])
+		w.(http.Flusher).Flush()
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		_ = conn.Close()
+		return true
+	})
+
+	dest := filepath.Join(t.TempDir(), "mcp-scan"+partialDownloadSuffix)
+	if err := downloadFile(newDownloadTestContext(t, t.TempDir()), server.URL, nil, dest, nil); err != nil {
+		t.Fatalf("expected interrupted download to be resumed: %v", err)
+	}
+	if got := readFile(t, dest); got != testBinaryContent {
+		t.Fatalf("unexpected content %q", got)
+	}
+}
+
+func TestDownloadFile_RangeIgnoredByServer(t *testing.T) {
+	withFastRetries(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, testBinaryContent)
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "mcp-scan"+partialDownloadSuffix)
+	if err := os.WriteFile(dest, []byte("stale partial content"), 0o600); err != nil {
+		t.Fatalf("failed to write partial download: %v", err)
+	}
+	if err := downloadFile(newDownloadTestContext(t, t.TempDir()), server.URL, nil, dest, nil); err != nil {
+		t.Fatalf("expected download to succeed: %v", err)
+	}
+	if got := readFile(t, dest); got != testBinaryContent {
+		t.Fatalf("expected partial file to be replaced, got %q", got)
+	}
+}
+
+func TestDownloadFile_PermanentFailure(t *testing.T) {
+	withFastRetries(t)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	err := downloadFile(newDownloadTestContext(t, t.TempDir()), server.URL, nil, filepath.Join(t.TempDir(), "dest"), nil)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Fatalf("expected no retries for a missing asset, got %d requests", requests.Load())
+	}
+}
+
+func TestDownloadFile_GivesUp(t *testing.T) {
+	withFastRetries(t)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := downloadFile(newDownloadTestContext(t, t.TempDir()), server.URL, nil, filepath.Join(t.TempDir(), "dest"), nil)
+	if err == nil {
+		t.Fatalf("expected download to fail")
+	}
+	if requests.Load() != maxDownloadAttempts {
+		t.Fatalf("expected %d attempts, got %d", maxDownloadAttempts, requests.Load())
+	}
+}
+
+func TestDownloadFile_CancelledWhileWaitingToRetry(t *testing.T) {
+	previous := downloadRetryDelay
+	downloadRetryDelay = time.Hour
+	t.Cleanup(func() { downloadRetryDelay = previous })
+
+	ctx, cancel := context.WithCancel(t.Context())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		cancel()
+	}))
+	defer server.Close()
+
+	err := downloadFile(newDownloadTestContextWith(t, t.TempDir(), ctx), server.URL, nil, filepath.Join(t.TempDir(), "dest"), nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the retry wait to end with the cancellation, got %v", err)
+	}
+}
+
+func TestContentRangeStart(t *testing.T) {
+	for header, want := range map[string]int64{
+		"bytes 10-35/36": 10,
+		"bytes 0-0/1":    0,
+		"bytes */36":     -1,
+		"":               -1,
+	} {
+		if got := contentRangeStart(header); got != want {
+			t.Errorf("contentRangeStart(%q) = %d, want %d", header, got, want)
+		}
+	}
+}
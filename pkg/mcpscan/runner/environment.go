@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// defaultPassEnv lists the variables the scanner and the MCP servers it launches get by default.
+// They are what interpreters and package managers like node, npx, python or uvx need to start;
+// credentials and tokens are left out unless passed explicitly.
+var defaultPassEnv = []string{
+	// POSIX
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "LANG", "LANGUAGE", "LC_*", "TERM", "TMPDIR", "TZ", "XDG_*",
+	// Windows
+	"SYSTEMROOT", "SYSTEMDRIVE", "WINDIR", "COMSPEC", "PATHEXT", "TEMP", "TMP", "USERPROFILE", "USERNAME",
+	"APPDATA", "LOCALAPPDATA", "PROGRAMDATA", "PROGRAMFILES", "PROGRAMFILES(X86)", "COMMONPROGRAMFILES",
+	"HOMEDRIVE", "HOMEPATH", "NUMBER_OF_PROCESSORS", "PROCESSOR_ARCHITECTURE", "OS",
+}
+
+// ValidatePassEnv checks that every pattern is a valid glob for variable names.
+func ValidatePassEnv(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil || strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("invalid environment variable pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// buildEnvironment keeps the variables of environ whose names match the default allowlist or one
+// of the extra patterns, e.g. "AWS_PROFILE" or "NVM_*". Names are matched case-insensitively on Windows.
+func buildEnvironment(environ, extraPatterns []string) []string {
+	patterns := append(append([]string{}, defaultPassEnv...), extraPatterns...)
+	env := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		name, _, found := strings.Cut(kv, "=")
+		if !found || name == "" {
+			continue
+		}
+		if matchesAnyPattern(name, patterns) {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+func matchesAnyPattern(name string, patterns []string) bool {
+	if runtime.GOOS == "windows" {
+		name = strings.ToUpper(name)
+	}
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if runtime.GOOS == "windows" {
+			pattern = strings.ToUpper(pattern)
+		}
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package runner //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
+)
+
+func TestBuildEnvironment(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin",
+		"HOME=/home/dev",
+		"LC_ALL=C",
+		"SNYK_TOKEN=secret",
+		"AWS_SECRET_ACCESS_KEY=secret",
+		"AWS_PROFILE=dev",
+		"NVM_DIR=/home/dev/.nvm",
+		"GITHUB_TOKEN=secret",
+		"malformed",
+	}
+
+	got := buildEnvironment(environ, []string{"AWS_PROFILE", "NVM_*"})
+	want := []string{"PATH=/usr/bin", "HOME=/home/dev", "LC_ALL=C", "AWS_PROFILE=dev", "NVM_DIR=/home/dev/.nvm"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected environment:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidatePassEnv(t *testing.T) {
+	if err := ValidatePassEnv([]string{"AWS_*", "NVM_DIR"}); err != nil {
+		t.Fatalf("expected valid patterns, got %v", err)
+	}
+	if err := ValidatePassEnv([]string{"AWS_[*"}); err == nil {
+		t.Fatalf("expected malformed pattern to be rejected")
+	}
+}
+
+func TestExecuteBinary_Environment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on windows")
+	}
+	t.Setenv("SNYK_TOKEN", "secret")
+	t.Setenv("AWS_PROFILE", "dev")
+	const script = "#!/bin/sh\nenv\n"
+	path := writeScript(t, t.TempDir(), script)
+
+	var stdout bytes.Buffer
+	_, err := ExecuteBinary(newMockInvocationContext(t), nil, Binary{
+		Version:   "0.4.2",
+		Checksum:  sha256Hex(script),
+		LocalPath: path,
+		PublicKey: signFile(t, path),
+		PassEnv:   []string{"AWS_*"},
+	}, &proxy.ProxyInfo{Port: 8080, Password: "pw", CertificateLocation: "/tmp/ca.crt"}, &stdout)
+	if err != nil {
+		t.Fatalf("failed to run binary: %v", err)
+	}
+
+	env := stdout.String()
+	for _, expected := range []string{"AWS_PROFILE=dev", "SNYK_CLI_USE=true", "HTTPS_PROXY=http://snykcli:pw@127.0.0.1:8080", "NODE_EXTRA_CA_CERTS=/tmp/ca.crt"} {
+		if !strings.Contains(env, expected) {
+			t.Errorf("expected %s in the scanner environment", expected)
+		}
+	}
+	if strings.Contains(env, "SNYK_TOKEN") {
+		t.Errorf("expected SNYK_TOKEN not to reach the scanner")
+	}
+}
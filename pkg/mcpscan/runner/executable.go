@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// verifiedExecutable is an open binary whose contents were hashed through the same file
+// descriptor that is later used to start it, so swapping the file at its path after the
+// verification has no effect on what is executed.
+type verifiedExecutable struct {
+	file *os.File
+	path string
+}
+
+// openVerifiedExecutable opens the binary at path and checks it against the expected checksum.
+func openVerifiedExecutable(path, checksum string) (*verifiedExecutable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mcp-scan binary %s: %w", path, err)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to hash mcp-scan binary %s: %w", path, err)
+	}
+	if actual := fmt.Sprintf("%x", h.Sum(nil)); !strings.EqualFold(actual, checksum) {
+		_ = f.Close()
+		return nil, fmt.Errorf("checksum verification failed for mcp-scan binary %s right before execution", path)
+	}
+
+	return &verifiedExecutable{file: f, path: path}, nil
+}
+
+// Command prepares the verified binary to be run with args.
+func (e *verifiedExecutable) Command(args ...string) *exec.Cmd {
+	return commandForFile(e.file, e.path, args)
+}
+
+func (e *verifiedExecutable) Close() error {
+	return e.file.Close()
+}
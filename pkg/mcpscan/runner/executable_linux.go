@@ -0,0 +1,36 @@
+//go:build linux
+
+package runner
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// commandForFile executes the already opened file rather than resolving its path again.
+// The child execs the descriptor of this process through procfs instead of inheriting it,
+// so neither the scanner nor the processes it starts are left with the descriptor open.
+// The path is only used as argv[0]. Without procfs the path is executed directly.
+func commandForFile(f *os.File, path string, args []string) *exec.Cmd {
+	//nolint:gosec // path is the verified scanner binary; args are passed as-is from the CLI.
+	cmd := exec.Command(path, args...)
+	fdPath := fmt.Sprintf("/proc/%d/fd/%d", os.Getpid(), f.Fd())
+	if _, err := os.Stat(fdPath); err == nil {
+		cmd.Path = fdPath
+	}
+	return cmd
+}
+
+// resolveThroughThread rewrites a descriptor path of commandForFile to be resolved through the
+// calling thread. A process confined by Landlock may only inspect processes of its own domain,
+// which the restricted thread starting it is part of but the rest of this process is not.
+func resolveThroughThread(path string) string {
+	if fd, ok := strings.CutPrefix(path, fmt.Sprintf("/proc/%d/fd/", os.Getpid())); ok {
+		return fmt.Sprintf("/proc/%d/task/%d/fd/%s", os.Getpid(), unix.Gettid(), fd)
+	}
+	return path
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+)
+
+// commandForFile executes the binary by path. The file stays open and the cache lock is
+// held while it runs, so the verified binary is not replaced by other CLI processes.
+func commandForFile(_ *os.File, path string, args []string) *exec.Cmd {
+	//nolint:gosec // path is the verified scanner binary; args are passed as-is from the CLI.
+	return exec.Command(path, args...)
+}
@@ -0,0 +1,184 @@
+package runner //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const testScript = "#!/bin/sh\necho \"original $1\"\n"
+
+func writeScript(t *testing.T, dir, contents string) string {
+	t.Helper()
+	path := filepath.Join(dir, "mcp-scan-test")
+	if err := os.WriteFile(path, []byte(contents), 0o700); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+	return path
+}
+
+func TestExecuteBinary_RunsInPlace(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on windows")
+	}
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+	path := writeScript(t, t.TempDir(), testScript)
+
+	var stdout bytes.Buffer
+	exitCode, err := ExecuteBinary(newMockInvocationContext(t), []string{"scan"}, Binary{
+		Version:   "0.4.2",
+		Checksum:  sha256Hex(testScript),
+		LocalPath: path,
+		PublicKey: signFile(t, path),
+	}, nil, &stdout)
+	if err != nil || exitCode != 0 {
+		t.Fatalf("expected binary to run, got exit code %d: %v", exitCode, err)
+	}
+	if strings.TrimSpace(stdout.String()) != "original scan" {
+		t.Fatalf("unexpected output %q", stdout.String())
+	}
+
+	leftovers, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to read temp dir: %v", err)
+	}
+	if len(leftovers) != 0 {
+		t.Fatalf("expected no temp copies of the binary, found %d file(s)", len(leftovers))
+	}
+}
+
+func TestVerifiedExecutable_IgnoresReplacedFile(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("executing the verified descriptor requires procfs")
+	}
+	dir := t.TempDir()
+	path := writeScript(t, dir, testScript)
+
+	executable, err := openVerifiedExecutable(path, sha256Hex(testScript))
+	if err != nil {
+		t.Fatalf("failed to verify executable: %v", err)
+	}
+	defer executable.Close()
+
+	// Swap the binary after verification, as a concurrent writer could.
+	replacement := filepath.Join(dir, "replacement")
+	if err := os.WriteFile(replacement, []byte("#!/bin/sh\necho tampered\n"), 0o700); err != nil {
+		t.Fatalf("failed to write replacement: %v", err)
+	}
+	if err := os.Rename(replacement, path); err != nil {
+		t.Fatalf("failed to replace binary: %v", err)
+	}
+
+	out, err := executable.Command("run").Output()
+	if err != nil {
+		t.Fatalf("failed to run verified executable: %v", err)
+	}
+	if strings.TrimSpace(string(out)) != "original run" {
+		t.Fatalf("expected the verified binary to run, got %q", out)
+	}
+}
+
+func TestVerifiedExecutable_DescriptorNotInherited(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("executing the verified descriptor requires procfs")
+	}
+	const script = "#!/bin/sh\nif [ -e /proc/$$/fd/3 ]; then echo inherited; else echo closed; fi\n"
+	path := writeScript(t, t.TempDir(), script)
+
+	executable, err := openVerifiedExecutable(path, sha256Hex(script))
+	if err != nil {
+		t.Fatalf("failed to verify executable: %v", err)
+	}
+	defer executable.Close()
+
+	out, err := executable.Command().Output()
+	if err != nil {
+		t.Fatalf("failed to run verified executable: %v", err)
+	}
+	if strings.TrimSpace(string(out)) != "closed" {
+		t.Fatalf("expected the binary's descriptor not to be passed to the scanner, got %q", out)
+	}
+}
+
+func TestOpenVerifiedExecutable_ChecksumMismatch(t *testing.T) {
+	path := writeScript(t, t.TempDir(), testScript)
+	if _, err := openVerifiedExecutable(path, sha256Hex("something else")); err == nil {
+		t.Fatalf("expected checksum mismatch to fail")
+	}
+}
+
+func TestSweepStaleTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-2 * staleTempFileAge)
+	files := map[string]bool{
+		"mcp-scan-123456789":          true,
+		"snyk-mcp-scan-cert-42.crt":   true,
+		"snyk-cli-cert-42.crt":        false,
+		"mcp-scan-0.4.2-linux-x86_64": false,
+		"unrelated-123":               false,
+	}
+	for name := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatalf("failed to age %s: %v", name, err)
+		}
+	}
+	recent := filepath.Join(dir, "snyk-mcp-scan-cert-7.crt")
+	if err := os.WriteFile(recent, nil, 0o600); err != nil {
+		t.Fatalf("failed to write recent cert: %v", err)
+	}
+
+	logger := zerolog.Nop()
+	if removed := SweepStaleTempFiles(&logger, dir, dir, ""); removed != 2 {
+		t.Fatalf("expected 2 stale files to be removed, got %d", removed)
+	}
+	for name, stale := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if stale != os.IsNotExist(err) {
+			t.Errorf("unexpected sweep result for %s: stale=%v, stat error=%v", name, stale, err)
+		}
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("expected certificate of a running scan to be kept: %v", err)
+	}
+}
+
+func TestExecuteBinary_Timeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on windows")
+	}
+	previous := killGracePeriod
+	killGracePeriod = 100 * time.Millisecond
+	t.Cleanup(func() { killGracePeriod = previous })
+
+	// The script ignores SIGTERM and starts a child, like a scanner with a hanging MCP server.
+	const script = "#!/bin/sh\ntrap '' TERM\nsleep 30 &\nwait\n"
+	path := writeScript(t, t.TempDir(), script)
+
+	start := time.Now()
+	exitCode, err := ExecuteBinary(newMockInvocationContext(t), nil, Binary{
+		Version:   "0.4.2",
+		Checksum:  sha256Hex(script),
+		LocalPath: path,
+		PublicKey: signFile(t, path),
+		Timeout:   200 * time.Millisecond,
+	}, nil, &bytes.Buffer{})
+	if !errors.Is(err, context.DeadlineExceeded) || exitCode != -1 {
+		t.Fatalf("expected the run to time out, got exit code %d: %v", exitCode, err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("expected the process group to be killed promptly, took %s", elapsed)
+	}
+}
@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrInterrupted is returned when the scanner was stopped by a signal sent to the CLI.
+var ErrInterrupted = errors.New("mcp-scan was interrupted")
+
+// killGracePeriod is how long the scanner and the MCP servers it started get to exit after
+// being asked to terminate before they are killed.
+var killGracePeriod = 5 * time.Second
+
+// runProcess starts cmd in its own process group, or job object on Windows, and waits for it.
+// Signals sent to the CLI are forwarded to the whole group, and cancelling ctx terminates it, so
+// neither the scanner nor the MCP servers it launched outlive the CLI. A non-nil sandbox
+// restricts the whole process tree.
+func runProcess(ctx context.Context, cmd *exec.Cmd, sandbox *sandboxPolicy, logger *zerolog.Logger) error {
+	configureProcess(cmd)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	done, err := startProcess(cmd, sandbox, logger)
+	if err != nil {
+		return err
+	}
+
+	var stopReason error
+	var kill <-chan time.Time
+	ctxDone := ctx.Done()
+	for {
+		select {
+		case err := <-done:
+			if stopReason != nil {
+				return stopReason
+			}
+			return err
+		case sig := <-signals:
+			logger.Debug().Str("signal", sig.String()).Msg("Forwarding signal to mcp-scan")
+			stopReason = ErrInterrupted
+			if err := signalProcessGroup(cmd.Process, sig); err != nil {
+				logger.Debug().Err(err).Msg("Failed to forward signal to mcp-scan")
+			}
+			if kill == nil {
+				kill = time.After(killGracePeriod)
+			}
+		case <-ctxDone:
+			ctxDone = nil
+			stopReason = ctx.Err()
+			logger.Debug().Err(stopReason).Msg("Terminating mcp-scan")
+			if err := signalProcessGroup(cmd.Process, terminateSignal); err != nil {
+				logger.Debug().Err(err).Msg("Failed to terminate mcp-scan")
+			}
+			kill = time.After(killGracePeriod)
+		case <-kill:
+			logger.Debug().Msg("mcp-scan did not exit in time, killing it")
+			if err := killProcessGroup(cmd.Process); err != nil {
+				return fmt.Errorf("failed to kill mcp-scan: %w", err)
+			}
+			kill = nil
+		}
+	}
+}
+
+// startProcess starts cmd, inside the sandbox if one is given, and reports its exit on the
+// returned channel.
+func startProcess(cmd *exec.Cmd, sandbox *sandboxPolicy, logger *zerolog.Logger) (<-chan error, error) {
+	if sandbox != nil {
+		return startSandboxed(cmd, sandbox, logger)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	if err := superviseProcess(cmd.Process); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, err
+	}
+	done := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		releaseProcess(cmd.Process)
+		done <- err
+	}()
+	return done, nil
+}
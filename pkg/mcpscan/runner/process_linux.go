@@ -0,0 +1,17 @@
+//go:build linux
+
+package runner
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureProcess puts the scanner in its own process group and has the kernel terminate it
+// should the CLI die without cleaning up.
+func configureProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid:   true,
+		Pdeathsig: syscall.SIGTERM,
+	}
+}
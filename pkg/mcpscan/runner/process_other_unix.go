@@ -0,0 +1,13 @@
+//go:build unix && !linux
+
+package runner
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureProcess puts the scanner in its own process group.
+func configureProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
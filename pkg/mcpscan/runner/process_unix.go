@@ -0,0 +1,31 @@
+//go:build unix
+
+package runner
+
+import (
+	"os"
+	"syscall"
+)
+
+var forwardedSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+
+const terminateSignal = syscall.SIGTERM
+
+// superviseProcess has nothing to set up; the process group is created when the scanner starts.
+func superviseProcess(_ *os.Process) error {
+	return nil
+}
+
+func releaseProcess(_ *os.Process) {}
+
+func signalProcessGroup(p *os.Process, sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return p.Signal(sig)
+	}
+	return syscall.Kill(-p.Pid, s)
+}
+
+func killProcessGroup(p *os.Process) error {
+	return syscall.Kill(-p.Pid, syscall.SIGKILL)
+}
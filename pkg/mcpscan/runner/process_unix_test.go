@@ -0,0 +1,37 @@
+//go:build unix
+
+package runner //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestExecuteBinary_ForwardsSignals(t *testing.T) {
+	const script = "#!/bin/sh\ntrap 'echo terminated; exit 143' TERM\nsleep 30 &\nwait\n"
+	path := writeScript(t, t.TempDir(), script)
+
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		_ = syscall.Kill(os.Getpid(), syscall.SIGTERM)
+	}()
+
+	var stdout bytes.Buffer
+	_, err := ExecuteBinary(newMockInvocationContext(t), nil, Binary{
+		Version:   "0.4.2",
+		Checksum:  sha256Hex(script),
+		LocalPath: path,
+		PublicKey: signFile(t, path),
+	}, nil, &stdout)
+	if !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("expected the run to be interrupted, got %v", err)
+	}
+	if strings.TrimSpace(stdout.String()) != "terminated" {
+		t.Fatalf("expected the scanner to receive the forwarded signal, got %q", stdout.String())
+	}
+}
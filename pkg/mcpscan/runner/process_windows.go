@@ -0,0 +1,97 @@
+//go:build windows
+
+package runner
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+var terminateSignal os.Signal = os.Kill
+
+// ntResumeProcess resumes a process started suspended. os/exec closes the handle of the main
+// thread that ResumeThread would need, so the whole process is resumed instead.
+var ntResumeProcess = windows.NewLazySystemDLL("ntdll.dll").NewProc("NtResumeProcess")
+
+// jobs maps the pid of a running scanner to the job object holding it and every process it starts.
+var jobs sync.Map
+
+// configureProcess starts the scanner suspended, so it is placed in its job object before it
+// can start any MCP server.
+func configureProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: windows.CREATE_SUSPENDED}
+}
+
+// superviseProcess places the suspended scanner in a job object and resumes it. The processes it
+// starts join the job, which kills all of them once it is terminated or its last handle is
+// closed, including when the CLI dies without cleaning up.
+func superviseProcess(p *os.Process) error {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create job object: %w", err)
+	}
+	if err := assignToJob(job, p.Pid); err != nil {
+		_ = windows.CloseHandle(job)
+		return err
+	}
+	jobs.Store(p.Pid, job)
+	return nil
+}
+
+func assignToJob(job windows.Handle, pid int) error {
+	info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{
+		BasicLimitInformation: windows.JOBOBJECT_BASIC_LIMIT_INFORMATION{
+			LimitFlags: windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE,
+		},
+	}
+	if _, err := windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+		return fmt.Errorf("failed to configure job object: %w", err)
+	}
+
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE|windows.PROCESS_SUSPEND_RESUME, false, uint32(pid))
+	if err != nil {
+		return fmt.Errorf("failed to open mcp-scan process: %w", err)
+	}
+	defer windows.CloseHandle(process) //nolint:errcheck // The handle is only used to set up the process.
+
+	if err := windows.AssignProcessToJobObject(job, process); err != nil {
+		return fmt.Errorf("failed to assign mcp-scan to its job object: %w", err)
+	}
+	if err := ntResumeProcess.Find(); err != nil {
+		return fmt.Errorf("failed to resume mcp-scan: %w", err)
+	}
+	if status, _, _ := ntResumeProcess.Call(uintptr(process)); status != 0 {
+		return fmt.Errorf("failed to resume mcp-scan: %w", windows.NTStatus(status))
+	}
+	return nil
+}
+
+// releaseProcess closes the job of the exited scanner, which kills the MCP servers it left behind.
+func releaseProcess(p *os.Process) {
+	if job, ok := jobs.LoadAndDelete(p.Pid); ok {
+		_ = windows.CloseHandle(job.(windows.Handle))
+	}
+}
+
+// signalProcessGroup stops the scanner and the processes it started; Windows cannot deliver
+// other signals to a process.
+func signalProcessGroup(p *os.Process, _ os.Signal) error {
+	return killProcessGroup(p)
+}
+
+func killProcessGroup(p *os.Process) error {
+	job, ok := jobs.Load(p.Pid)
+	if !ok {
+		return p.Kill()
+	}
+	return windows.TerminateJobObject(job.(windows.Handle), 1)
+}
@@ -11,26 +11,71 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"runtime"
 	"strings"
 	"time"
 
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/cache"
+	mcpscan_errors "github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/errors"
 	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
-	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/ui"
 	"github.com/snyk/go-application-framework/pkg/workflow"
 )
 
+// DefaultDownloadURL is the download location template for scanner release assets.
+const DefaultDownloadURL = "https://github.com/snyk/agent-scan/releases/download/{tag}/{asset}"
+
+// DefaultLockTimeout bounds how long a run waits for another process downloading the same binary.
+const DefaultLockTimeout = 5 * time.Minute
+
+// Binary identifies the scanner binary to run, the checksum it must match and where to obtain it.
+type Binary struct {
+	Version string
+	// Asset is the name of the release asset for the current platform, as listed in the scanner manifest.
+	Asset    string
+	Checksum string
+	// LocalPath points at a pre-provisioned binary that is used instead of the cache or a download.
+	LocalPath string
+	// DownloadURL is a template for the asset location supporting {version}, {tag} and {asset}
+	// placeholders. A URL without placeholders is treated as a base URL mirroring the release layout.
+	DownloadURL string
+	// DownloadHeaders are additional "Name: value" headers sent with the download, e.g. for mirror authentication.
+	DownloadHeaders []string
+	// PublicKey is the minisign public key release assets are signed with. When set, a binary is only
+	// used after its detached signature has been verified; the checksum is always required.
+	PublicKey string
+	// LockTimeout bounds the wait for the cache lock held by other processes, DefaultLockTimeout if zero.
+	LockTimeout time.Duration
+	// Timeout bounds a single run of the binary; zero means no limit.
+	Timeout time.Duration
+	// PassEnv lists glob patterns of environment variables passed on in addition to the default allowlist.
+	PassEnv []string
+	// Sandbox confines the binary and the MCP servers it starts, see SandboxSupported.
+	Sandbox bool
+}
+
+func (b Binary) lockTimeout() time.Duration {
+	if b.LockTimeout <= 0 {
+		return DefaultLockTimeout
+	}
+	return b.LockTimeout
+}
+
 type githubAsset struct {
 	Name               string `json:"name"`
 	BrowserDownloadURL string `json:"browser_download_url"`
 }
 
-func httpGet(ctx workflow.InvocationContext, url string) (*http.Response, error) {
+func httpGet(ctx workflow.InvocationContext, url string, headers http.Header) (*http.Response, error) {
 	client := ctx.GetNetworkAccess().GetHttpClient()
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, http.NoBody)
+	req, err := http.NewRequestWithContext(ctx.Context(), http.MethodGet, url, http.NoBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
+	for name, values := range headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -40,44 +85,111 @@ func httpGet(ctx workflow.InvocationContext, url string) (*http.Response, error)
 	return resp, nil
 }
 
-func platformAssetMatcher() (prefix, suffix string, err error) {
-	switch runtime.GOOS {
-	case "linux":
-		switch runtime.GOARCH {
-		case "amd64":
-			return "mcp-scan-", "-linux-x86_64", nil
-		default:
-			return "", "", fmt.Errorf("unsupported linux architecture: %s", runtime.GOARCH)
+// parseHeaders converts "Name: value" strings into HTTP headers.
+func parseHeaders(raw []string) (http.Header, error) {
+	headers := http.Header{}
+	for _, h := range raw {
+		name, value, found := strings.Cut(h, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid download header %q, expected \"Name: value\"", h)
 		}
-	case "darwin":
-		switch runtime.GOARCH {
-		case "arm64":
-			return "mcp-scan-", "-macos-arm64", nil
-		default:
-			return "", "", fmt.Errorf("unsupported darwin architecture: %s", runtime.GOARCH)
-		}
-	default:
-		return "", "", fmt.Errorf("unsupported platform: %s/%s", runtime.GOOS, runtime.GOARCH)
+		headers.Add(name, strings.TrimSpace(value))
 	}
+	return headers, nil
 }
 
-func fetchAssetForVersionAndPlatform(_ workflow.InvocationContext, version string) (*githubAsset, error) {
-	prefix, suffix, err := platformAssetMatcher()
+// renderDownloadURL fills the download URL template for the given release asset.
+func renderDownloadURL(template, version, tag, assetName string) (string, error) {
+	if template == "" {
+		template = DefaultDownloadURL
+	}
+	if !strings.Contains(template, "{") {
+		template = strings.TrimRight(template, "/") + "/{tag}/{asset}"
+	}
+
+	rendered := strings.NewReplacer(
+		"{version}", url.PathEscape(version),
+		"{tag}", url.PathEscape(tag),
+		"{asset}", url.PathEscape(assetName),
+	).Replace(template)
+
+	parsed, err := url.Parse(rendered)
 	if err != nil {
-		return nil, err
+		return "", fmt.Errorf("invalid scanner download URL %q: %w", template, err)
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return "", fmt.Errorf("invalid scanner download URL %q: scheme must be http or https", template)
+	}
+	return rendered, nil
+}
+
+// AssetURL returns the URL the binary is downloaded from when it is not cached yet.
+func (b Binary) AssetURL() (string, error) {
+	asset, err := fetchAssetForVersionAndPlatform(nil, b.Version, b.Asset, b.DownloadURL)
+	if err != nil {
+		return "", err
+	}
+	return asset.BrowserDownloadURL, nil
+}
+
+// VerifyLocalBinary checks the pre-provisioned binary against the pinned checksum and its signature.
+func VerifyLocalBinary(ctx workflow.InvocationContext, binary Binary) error {
+	_, err := useLocalBinary(ctx, binary)
+	return err
+}
+
+// VerifyCachedSignature checks a cached binary against the signature stored next to it. Without a
+// public key there is nothing to verify and the pinned checksum is the only check.
+func VerifyCachedSignature(path string, binary Binary) error {
+	if binary.PublicKey == "" {
+		return nil
+	}
+	return verifyStoredSignature(path, binary.PublicKey)
+}
+
+// CheckDownload requests the first byte of the binary from its asset URL to check that it can be
+// downloaded. A ranged GET is used rather than HEAD as not every mirror answers HEAD requests for
+// release assets; servers ignoring the range are cut off by closing the body right away.
+func CheckDownload(ctx workflow.InvocationContext, binary Binary) error {
+	assetURL, err := binary.AssetURL()
+	if err != nil {
+		return err
+	}
+	headers, err := parseHeaders(binary.DownloadHeaders)
+	if err != nil {
+		return err
+	}
+	headers.Set("Range", "bytes=0-0")
+	resp, err := httpGet(ctx, assetURL, headers)
+	if err != nil {
+		return err
 	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+func fetchAssetForVersionAndPlatform(_ workflow.InvocationContext, version, assetName, downloadURL string) (*githubAsset, error) {
 	trimmedVersion := strings.TrimSpace(version)
 	if trimmedVersion == "" {
 		return nil, fmt.Errorf("version must not be empty")
 	}
+	if assetName == "" {
+		return nil, fmt.Errorf("no mcp-scan release asset for version %s on this platform", trimmedVersion)
+	}
 
-	assetName := prefix + trimmedVersion + suffix
 	tag := "v" + trimmedVersion
-	downloadURL := "https://github.com/snyk/agent-scan/releases/download/" + url.PathEscape(tag) + "/" + url.PathEscape(assetName)
+	browserDownloadURL, err := renderDownloadURL(downloadURL, trimmedVersion, tag, assetName)
+	if err != nil {
+		return nil, err
+	}
 
 	return &githubAsset{
 		Name:               assetName,
-		BrowserDownloadURL: downloadURL,
+		BrowserDownloadURL: browserDownloadURL,
 	}, nil
 }
 
@@ -97,65 +209,129 @@ func verifyFileChecksum(path, expected string) (bool, error) {
 	return strings.EqualFold(actual, expected), nil
 }
 
+// verifyStoredSignature checks the binary at path against the detached signature stored next to it.
+func verifyStoredSignature(path, publicKey string) error {
+	signature, err := readSignatureFile(path + cache.SignatureSuffix)
+	if err != nil {
+		return err
+	}
+	return verifyFileSignature(path, signature, publicKey)
+}
+
+// useLocalBinary verifies a pre-provisioned mcp-scan binary against the pinned checksum and, when a
+// public key is set, its signature.
+func useLocalBinary(ctx workflow.InvocationContext, binary Binary) (string, error) {
+	logger := ctx.GetEnhancedLogger()
+	info, err := os.Stat(binary.LocalPath)
+	if err != nil {
+		return "", mcpscan_errors.NewScannerUnavailableError(fmt.Sprintf("The mcp-scan binary at %s is not accessible.", binary.LocalPath), err).SnykError
+	}
+	if !info.Mode().IsRegular() {
+		return "", mcpscan_errors.NewScannerUnavailableError(fmt.Sprintf("The mcp-scan binary at %s is not a regular file.", binary.LocalPath), nil).SnykError
+	}
+
+	ok, err := verifyFileChecksum(binary.LocalPath, binary.Checksum)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify checksum of local mcp-scan binary: %w", err)
+	}
+	if !ok {
+		logger.Error().Str("path", binary.LocalPath).Msg("Checksum verification failed for local mcp-scan binary")
+		return "", fmt.Errorf("checksum verification failed for local mcp-scan binary %s, expected mcp-scan version %s", binary.LocalPath, binary.Version)
+	}
+
+	if binary.PublicKey != "" {
+		if err := verifyStoredSignature(binary.LocalPath, binary.PublicKey); err != nil {
+			logger.Error().Err(err).Str("path", binary.LocalPath).Msg("Signature verification failed for local mcp-scan binary")
+			return "", fmt.Errorf("signature verification failed for local mcp-scan binary: %w", err)
+		}
+	}
+
+	logger.Debug().Str("path", binary.LocalPath).Msg("Using local mcp-scan binary")
+	return binary.LocalPath, nil
+}
+
+func readSignatureFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open signature %s: %w", path, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxSignatureSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// downloadSignature fetches the detached signature published next to a release asset.
+func downloadSignature(ctx workflow.InvocationContext, assetURL string, headers http.Header) ([]byte, error) {
+	resp, err := httpGet(ctx, assetURL+cache.SignatureSuffix, headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download signature: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download signature: unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSignatureSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+	return data, nil
+}
+
 // getOrDownloadBinary locates, downloads, verifies and caches the mcp-scan binary for this platform.
 //
 //nolint:gocyclo // The control flow is a bit involved but kept together for clarity.
-func getOrDownloadBinary(ctx workflow.InvocationContext, version, checksum string) (string, error) {
+func getOrDownloadBinary(ctx workflow.InvocationContext, binary Binary) (string, error) {
+	if binary.LocalPath != "" {
+		return useLocalBinary(ctx, binary)
+	}
+
 	logger := ctx.GetEnhancedLogger()
-	asset, err := fetchAssetForVersionAndPlatform(ctx, version)
+	checksum := binary.Checksum
+	asset, err := fetchAssetForVersionAndPlatform(ctx, binary.Version, binary.Asset, binary.DownloadURL)
+	if err != nil {
+		return "", err
+	}
+	downloadHeaders, err := parseHeaders(binary.DownloadHeaders)
 	if err != nil {
 		return "", err
 	}
 
 	config := ctx.GetConfiguration()
-	cacheDir := config.GetString(configuration.CACHE_PATH)
-	if cacheDir == "" {
-		cacheDir = os.TempDir()
-	}
+	cacheDir := cache.Dir(config)
 	if mkdirErr := os.MkdirAll(cacheDir, 0o755); mkdirErr != nil {
 		return "", fmt.Errorf("failed to create cache directory %s: %w", cacheDir, mkdirErr)
 	}
 	cachePath := filepath.Join(cacheDir, asset.Name)
-	info, err := os.Stat(cachePath)
-	progressBar := ctx.GetUserInterface().NewProgressBar()
-	//nolint:nestif // The nested structure keeps cache verification and download logic closely related.
-	if err == nil && info.Mode().IsRegular() {
-		if perr := progressBar.UpdateProgress(0.1); perr != nil {
-			logger.Debug().Err(perr).Msg("failed to update progress bar while verifying cached binary")
-		}
-		progressBar.SetTitle("Verifying cached mcp-scan binary")
-
-		ok, verr := verifyFileChecksum(cachePath, checksum)
-		if verr != nil {
-			logger.Error().Err(verr).Msg("Failed to verify checksum of cached mcp-scan binary")
-			if cerr := progressBar.Clear(); cerr != nil {
-				logger.Debug().Err(cerr).Msg("failed to clear progress bar after cached checksum verification error")
-			}
-			return "", fmt.Errorf("failed to verify checksum of cached mcp-scan binary: %w", verr)
-		}
-		if !ok {
-			logger.Error().Msg("Checksum verification failed for cached mcp-scan binary")
-			if cerr := progressBar.Clear(); cerr != nil {
-				logger.Debug().Err(cerr).Msg("failed to clear progress bar after cached checksum mismatch")
-			}
-			return "", fmt.Errorf("checksum verification failed for cached mcp-scan binary")
-		}
 
-		if perr := progressBar.UpdateProgress(1.0); perr != nil {
-			logger.Debug().Err(perr).Msg("failed to update progress bar after verifying cached binary")
-		}
-		progressBar.SetTitle("Using cached mcp-scan binary")
+	// Parallel runs share the cache. The cached binary is checked under the shared lock, so runs
+	// using it do not wait for each other; only a download takes the exclusive lock.
+	lockTimeout := binary.lockTimeout()
+	unlock, err := cache.LockShared(ctx.Context(), cachePath, lockTimeout)
+	if err != nil {
+		return "", lockError(cachePath, lockTimeout, err, "finish downloading the mcp-scan binary")
+	}
+	progressBar := ctx.GetUserInterface().NewProgressBar()
+	cached, err := useCachedBinary(ctx, cachePath, binary, progressBar)
+	unlock()
+	if err != nil || cached {
+		return cachePath, err
+	}
 
-		time.AfterFunc(800*time.Millisecond, func() {
-			if cerr := progressBar.Clear(); cerr != nil {
-				logger.Debug().Err(cerr).Msg("failed to clear progress bar after using cached binary")
-			}
-		})
-		logger.Debug().Str("path", cachePath).Msg("Using cached mcp-scan binary")
-		return cachePath, nil
+	unlock, err = cache.Lock(ctx.Context(), cachePath, lockTimeout)
+	if err != nil {
+		return "", lockError(cachePath, lockTimeout, err, "stop using the mcp-scan binary so it can be replaced")
 	}
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return "", fmt.Errorf("failed to stat cached binary: %w", err)
+	defer unlock()
+
+	// Another run may have downloaded the binary while this one waited for the exclusive lock.
+	if cached, err = useCachedBinary(ctx, cachePath, binary, progressBar); err != nil || cached {
+		return cachePath, err
 	}
 
 	if perr := progressBar.UpdateProgress(0.2); perr != nil {
@@ -168,59 +344,79 @@ func getOrDownloadBinary(ctx workflow.InvocationContext, version, checksum strin
 			logger.Debug().Err(outErr).Msg("failed to output download disclaimer")
 		}
 	}
-	resp, err := httpGet(ctx, asset.BrowserDownloadURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to download binary: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to download binary: unexpected status %s", resp.Status)
-	}
-
-	tmpDownload, err := os.CreateTemp(cacheDir, asset.Name+".download-*")
+	partialPath := cachePath + partialDownloadSuffix
+	lastProgress := 0.2
+	err = downloadFile(ctx, asset.BrowserDownloadURL, downloadHeaders, partialPath, func(done, total int64) {
+		if total <= 0 {
+			return
+		}
+		// Downloading covers the progress between 0.2 and 0.9, verification the rest.
+		progress := 0.2 + 0.7*float64(done)/float64(total)
+		if progress-lastProgress < 0.01 {
+			return
+		}
+		lastProgress = progress
+		if perr := progressBar.UpdateProgress(progress); perr != nil {
+			logger.Debug().Err(perr).Msg("failed to update progress bar during download")
+		}
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp download file: %w", err)
-	}
-
-	_, copyErr := io.Copy(tmpDownload, resp.Body)
-	closeErr := tmpDownload.Close()
-	if copyErr != nil {
-		_ = os.Remove(tmpDownload.Name())
-		return "", fmt.Errorf("failed to write downloaded binary: %w", copyErr)
-	}
-	if closeErr != nil {
-		_ = os.Remove(tmpDownload.Name())
-		return "", fmt.Errorf("failed to close downloaded binary: %w", closeErr)
+		clearProgressBar(ctx, progressBar)
+		return "", unavailableError(cachePath, fmt.Errorf("failed to download binary: %w", err))
 	}
 	progressBar.SetTitle("Verifying downloaded mcp-scan binary")
 	if perr := progressBar.UpdateProgress(0.9); perr != nil {
 		logger.Debug().Err(perr).Msg("failed to update progress bar before checksum verification")
 	}
 
-	ok, verr := verifyFileChecksum(tmpDownload.Name(), checksum)
+	ok, verr := verifyFileChecksum(partialPath, checksum)
 	if verr != nil {
-		_ = os.Remove(tmpDownload.Name())
+		_ = os.Remove(partialPath)
 		if cerr := progressBar.Clear(); cerr != nil {
 			logger.Debug().Err(cerr).Msg("failed to clear progress bar after downloaded checksum verification error")
 		}
 		return "", fmt.Errorf("failed to verify downloaded binary checksum: %w", verr)
 	}
 	if !ok {
-		_ = os.Remove(tmpDownload.Name())
+		_ = os.Remove(partialPath)
 		if cerr := progressBar.Clear(); cerr != nil {
 			logger.Debug().Err(cerr).Msg("failed to clear progress bar after downloaded checksum mismatch")
 		}
 		return "", fmt.Errorf("checksum verification failed for downloaded binary")
 	}
 
-	if err := os.Chmod(tmpDownload.Name(), 0o700); err != nil {
-		_ = os.Remove(tmpDownload.Name())
+	var signature []byte
+	if binary.PublicKey != "" {
+		progressBar.SetTitle("Verifying mcp-scan binary signature")
+		var serr error
+		signature, serr = downloadSignature(ctx, asset.BrowserDownloadURL, downloadHeaders)
+		if serr == nil {
+			serr = verifyFileSignature(partialPath, signature, binary.PublicKey)
+		}
+		if serr != nil {
+			_ = os.Remove(partialPath)
+			logger.Error().Err(serr).Msg("Signature verification failed for downloaded mcp-scan binary")
+			clearProgressBar(ctx, progressBar)
+			return "", fmt.Errorf("signature verification failed for downloaded binary: %w", serr)
+		}
+	}
+
+	if err := os.Chmod(partialPath, 0o700); err != nil {
+		_ = os.Remove(partialPath)
 		return "", fmt.Errorf("failed to chmod downloaded binary: %w", err)
 	}
 
-	if err := os.Rename(tmpDownload.Name(), cachePath); err != nil {
-		_ = os.Remove(tmpDownload.Name())
+	// The signature is kept next to the cached binary so later runs can verify it again.
+	signaturePath := cachePath + cache.SignatureSuffix
+	if signature != nil {
+		if err := os.WriteFile(signaturePath, signature, 0o600); err != nil {
+			_ = os.Remove(partialPath)
+			return "", fmt.Errorf("failed to store signature of downloaded binary: %w", err)
+		}
+	}
+	if err := os.Rename(partialPath, cachePath); err != nil {
+		_ = os.Remove(partialPath)
+		_ = os.Remove(signaturePath)
 		return "", fmt.Errorf("failed to move downloaded binary into cache: %w", err)
 	}
 	if perr := progressBar.UpdateProgress(1.0); perr != nil {
@@ -234,60 +430,143 @@ func getOrDownloadBinary(ctx workflow.InvocationContext, version, checksum strin
 	return cachePath, nil
 }
 
-// ExecuteBinary writes the binary to a temp file and runs it.
-// Returns the exit code and error. If the binary exits with a non-zero code,
-// the error will be non-nil and contain the exit code information.
-func ExecuteBinary(ctx workflow.InvocationContext, args []string, version, checksum string, proxyInfo interface{}) (int, error) {
+// maxAcquireAttempts bounds how often a cached binary removed by another process right after it
+// was prepared is prepared again.
+const maxAcquireAttempts = 3
+
+// useCachedBinary verifies the binary in the cache and reports whether it can be used. A missing
+// binary, or one whose signature cannot be verified, needs to be downloaded.
+func useCachedBinary(ctx workflow.InvocationContext, cachePath string, binary Binary, progressBar ui.ProgressBar) (bool, error) {
 	logger := ctx.GetEnhancedLogger()
-	binaryPath, err := getOrDownloadBinary(ctx, version, checksum)
-	if err != nil {
-		logger.Error().Err(err).Msg("Failed to prepare mcp-scan binary")
-		return -1, err
+	checksum := binary.Checksum
+	info, err := os.Stat(cachePath)
+	//nolint:nestif // The nested structure keeps the checksum and signature verification together.
+	if err == nil && info.Mode().IsRegular() {
+		if perr := progressBar.UpdateProgress(0.1); perr != nil {
+			logger.Debug().Err(perr).Msg("failed to update progress bar while verifying cached binary")
+		}
+		progressBar.SetTitle("Verifying cached mcp-scan binary")
+
+		ok, verr := verifyFileChecksum(cachePath, checksum)
+		if verr != nil {
+			logger.Error().Err(verr).Msg("Failed to verify checksum of cached mcp-scan binary")
+			if cerr := progressBar.Clear(); cerr != nil {
+				logger.Debug().Err(cerr).Msg("failed to clear progress bar after cached checksum verification error")
+			}
+			return false, fmt.Errorf("failed to verify checksum of cached mcp-scan binary: %w", verr)
+		}
+		if !ok {
+			logger.Error().Msg("Checksum verification failed for cached mcp-scan binary")
+			if cerr := progressBar.Clear(); cerr != nil {
+				logger.Debug().Err(cerr).Msg("failed to clear progress bar after cached checksum mismatch")
+			}
+			return false, fmt.Errorf("checksum verification failed for cached mcp-scan binary")
+		}
+
+		// With a public key, the signature stored with the binary is checked as well, so a binary
+		// placed in the cache directory by anything but a verified download is not run. Without a
+		// valid signature the binary is downloaded again.
+		if serr := VerifyCachedSignature(cachePath, binary); serr != nil {
+			logger.Warn().Err(serr).Str("path", cachePath).Msg("Signature verification failed for cached mcp-scan binary, downloading it again")
+		} else {
+			if perr := progressBar.UpdateProgress(1.0); perr != nil {
+				logger.Debug().Err(perr).Msg("failed to update progress bar after verifying cached binary")
+			}
+			progressBar.SetTitle("Using cached mcp-scan binary")
+
+			time.AfterFunc(800*time.Millisecond, func() {
+				if cerr := progressBar.Clear(); cerr != nil {
+					logger.Debug().Err(cerr).Msg("failed to clear progress bar after using cached binary")
+				}
+			})
+			logger.Debug().Str("path", cachePath).Msg("Using cached mcp-scan binary")
+			return true, nil
+		}
 	}
-	logger.Debug().Str("binaryPath", binaryPath).Msg("Executing mcp-scan binary")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("failed to stat cached binary: %w", err)
+	}
+	return false, nil
+}
 
-	// 1. Create a temporary file
-	// The "*" is a placeholder for a random string to ensure uniqueness
-	tmpFile, err := os.CreateTemp("", "mcp-scan-*")
-	if err != nil {
-		return -1, fmt.Errorf("failed to create temp file: %w", err)
+// lockError explains a lock on the cached binary that could not be taken in time.
+func lockError(cachePath string, timeout time.Duration, err error, waitingFor string) error {
+	if !errors.Is(err, cache.ErrLockTimeout) {
+		return err
 	}
+	msg := fmt.Sprintf("Timed out after %s waiting for another Snyk CLI process to %s at %s. "+
+		"Retry once it has finished, or provide a pre-provisioned binary with --scanner-binary.", timeout, waitingFor, cachePath)
+	return mcpscan_errors.NewScannerUnavailableError(msg, err).SnykError
+}
 
-	// 2. Ensure cleanup happens after execution
-	// We use a closure to capture the filename
-	// defer func() {
-	// 	_ = os.Remove(tmpFile.Name())
-	// }()
+// acquireBinary prepares the mcp-scan binary and returns it with a shared cache lock held, which
+// keeps prune, clear and other downloads from touching it while it runs. The locks taken while
+// preparing are released before it is returned, so the binary is only used if it is still in place
+// once the shared lock is held. The returned function releases the lock.
+func acquireBinary(ctx workflow.InvocationContext, binary Binary) (string, func(), error) {
+	if binary.LocalPath != "" {
+		path, err := useLocalBinary(ctx, binary)
+		return path, func() {}, err
+	}
 
-	// 3. Write the embedded bytes to the temp file
-	src, err := os.Open(binaryPath)
-	if err != nil {
-		_ = tmpFile.Close()
-		return -1, fmt.Errorf("failed to open binary for copying: %w", err)
+	for attempt := 1; ; attempt++ {
+		path, err := getOrDownloadBinary(ctx, binary)
+		if err != nil {
+			return "", nil, err
+		}
+		unlock, err := cache.LockShared(ctx.Context(), path, binary.lockTimeout())
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to lock mcp-scan binary: %w", err)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return path, unlock, nil
+		}
+		unlock()
+		ctx.GetEnhancedLogger().Debug().Str("path", path).Int("attempt", attempt).Msg("Cached mcp-scan binary was removed before it could be locked")
+		if attempt == maxAcquireAttempts {
+			return "", nil, fmt.Errorf("mcp-scan binary %s was removed from the cache by another process while preparing it", path)
+		}
 	}
-	defer src.Close()
+}
+
+// unavailableError explains that the binary can neither be taken from the cache nor downloaded.
+func unavailableError(cachePath string, cause error) error {
+	msg := fmt.Sprintf("The mcp-scan binary is not cached at %s and could not be downloaded. "+
+		"On hosts without internet access, provide a pre-provisioned binary with --scanner-binary or SNYK_MCP_SCAN_BINARY.", cachePath)
+	return mcpscan_errors.NewScannerUnavailableError(msg, cause).SnykError
+}
 
-	if _, copyErr := io.Copy(tmpFile, src); copyErr != nil {
-		_ = tmpFile.Close()
-		return -1, fmt.Errorf("failed to write binary: %w", copyErr)
+func clearProgressBar(ctx workflow.InvocationContext, progressBar ui.ProgressBar) {
+	if cerr := progressBar.Clear(); cerr != nil {
+		ctx.GetEnhancedLogger().Debug().Err(cerr).Msg("failed to clear progress bar")
 	}
+}
 
-	// Close the file descriptor so we can execute it
-	if closeErr := tmpFile.Close(); closeErr != nil {
-		return -1, fmt.Errorf("failed to close file: %w", closeErr)
+// ExecuteBinary verifies the cached or pre-provisioned binary and runs it in place.
+// The binary's standard output is written to stdout, or to os.Stdout if stdout is nil.
+// Returns the exit code and error. If the binary exits with a non-zero code,
+// the error will be non-nil and contain the exit code information.
+func ExecuteBinary(ctx workflow.InvocationContext, args []string, binary Binary, proxyInfo interface{}, stdout io.Writer) (int, error) {
+	logger := ctx.GetEnhancedLogger()
+	binaryPath, unlock, err := acquireBinary(ctx, binary)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to prepare mcp-scan binary")
+		return -1, err
 	}
+	defer unlock()
+	logger.Debug().Str("binaryPath", binaryPath).Msg("Executing mcp-scan binary")
 
-	// 4. Make the file executable (0700 = rwx for user)
-	if chmodErr := os.Chmod(tmpFile.Name(), 0o700); chmodErr != nil {
-		return -1, fmt.Errorf("failed to chmod: %w", chmodErr)
+	executable, err := openVerifiedExecutable(binaryPath, binary.Checksum)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to verify mcp-scan binary before execution")
+		return -1, err
 	}
+	defer executable.Close()
 
-	// 5. Prepare the command
-	//nolint:gosec // tmpFile is a local executable we've just written and chmodded; args are passed as-is from the CLI.
-	cmd := exec.Command(tmpFile.Name(), args...)
+	cmd := executable.Command(args...)
 
-	// Set base environment variables
-	cmd.Env = append(os.Environ(), "SNYK_CLI_USE=true")
+	// Set base environment variables; only allowlisted variables reach the scanner and its MCP servers
+	cmd.Env = append(buildEnvironment(os.Environ(), binary.PassEnv), "SNYK_CLI_USE=true")
 
 	// Configure proxy if provided
 	if proxyInfo != nil {
@@ -314,13 +593,39 @@ func ExecuteBinary(ctx workflow.InvocationContext, args []string, version, check
 	}
 
 	// Connect standard input/output if you want to see the binary's output
-	cmd.Stdout = os.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	cmd.Stdout = stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
 
-	// 6. Run and capture exit code
-	err = cmd.Run()
+	var sandbox *sandboxPolicy
+	if binary.Sandbox {
+		var removeScratchDir func()
+		sandbox, removeScratchDir, err = newSandboxPolicy(func(message string) {
+			logger.Warn().Msg("mcp-scan sandbox " + message)
+			fmt.Fprintf(os.Stderr, "Warning: mcp-scan sandbox %s\n", message)
+		})
+		if err != nil {
+			return -1, fmt.Errorf("failed to create sandbox scratch directory: %w", err)
+		}
+		defer removeScratchDir()
+		cmd.Env = append(cmd.Env, sandbox.environment()...)
+	}
+
+	// Run and capture exit code, stopping the scanner when the CLI is cancelled or the run times out
+	runCtx := ctx.Context()
+	if binary.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(runCtx, binary.Timeout)
+		defer cancel()
+	}
+	err = runProcess(runCtx, cmd, sandbox, logger)
 	exitCode := 0
+	if errors.Is(err, ErrInterrupted) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return -1, err
+	}
 	if err != nil {
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
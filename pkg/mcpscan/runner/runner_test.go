@@ -1,11 +1,28 @@
 package runner //nolint:testpackage // tests need access to internal helpers
 
 import (
+	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/snyk/error-catalog-golang-public/snyk_errors"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/cache"
 )
 
 const testHelloWorld = "hello world"
@@ -65,3 +82,407 @@ func TestVerifyFileChecksum_Mismatch(t *testing.T) {
 		t.Fatalf("verifyFileChecksum reported success for mismatched checksum")
 	}
 }
+
+func newMockInvocationContext(t *testing.T) *mocks.MockInvocationContext {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	logger := zerolog.Nop()
+	ictx := mocks.NewMockInvocationContext(ctrl)
+	ictx.EXPECT().GetEnhancedLogger().Return(&logger).AnyTimes()
+	ictx.EXPECT().Context().Return(context.Background()).AnyTimes()
+	return ictx
+}
+
+func sha256Hex(contents string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(contents)))
+}
+
+func TestGetOrDownloadBinary_LocalBinary(t *testing.T) {
+	path := writeTempFile(t, testHelloWorld)
+
+	got, err := getOrDownloadBinary(newMockInvocationContext(t), Binary{
+		Version:   "0.4.2",
+		Checksum:  sha256Hex(testHelloWorld),
+		LocalPath: path,
+		PublicKey: signFile(t, path),
+	})
+	if err != nil {
+		t.Fatalf("getOrDownloadBinary returned error for verified local binary: %v", err)
+	}
+	if got != path {
+		t.Fatalf("expected local binary path %q, got %q", path, got)
+	}
+}
+
+func TestGetOrDownloadBinary_LocalBinaryChecksumMismatch(t *testing.T) {
+	path := writeTempFile(t, testHelloWorld)
+
+	_, err := getOrDownloadBinary(newMockInvocationContext(t), Binary{
+		Version:   "0.4.2",
+		Checksum:  sha256Hex("something else"),
+		LocalPath: path,
+		PublicKey: signFile(t, path),
+	})
+	if err == nil {
+		t.Fatalf("expected checksum mismatch of local binary to fail")
+	}
+}
+
+func TestGetOrDownloadBinary_LocalBinaryMissing(t *testing.T) {
+	_, err := getOrDownloadBinary(newMockInvocationContext(t), Binary{
+		Version:   "0.4.2",
+		Checksum:  sha256Hex(testHelloWorld),
+		LocalPath: filepath.Join(t.TempDir(), "mcp-scan"),
+		PublicKey: newTestSigningKey(t).publicKey,
+	})
+
+	var snykErr snyk_errors.Error
+	if !errors.As(err, &snykErr) {
+		t.Fatalf("expected an error catalog error for a missing local binary, got %v", err)
+	}
+}
+
+func newDownloadTestContext(t *testing.T, cacheDir string) *mocks.MockInvocationContext {
+	t.Helper()
+	return newDownloadTestContextWith(t, cacheDir, t.Context())
+}
+
+func newDownloadTestContextWith(t *testing.T, cacheDir string, ctx context.Context) *mocks.MockInvocationContext {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	logger := zerolog.Nop()
+
+	config := configuration.NewWithOpts()
+	config.Set(configuration.CACHE_PATH, cacheDir)
+	config.Set("json", true)
+
+	progressBar := mocks.NewMockProgressBar(ctrl)
+	progressBar.EXPECT().UpdateProgress(gomock.Any()).Return(nil).AnyTimes()
+	progressBar.EXPECT().SetTitle(gomock.Any()).AnyTimes()
+	progressBar.EXPECT().Clear().Return(nil).AnyTimes()
+	userInterface := mocks.NewMockUserInterface(ctrl)
+	userInterface.EXPECT().NewProgressBar().Return(progressBar).AnyTimes()
+
+	networkAccess := mocks.NewMockNetworkAccess(ctrl)
+	networkAccess.EXPECT().GetHttpClient().Return(http.DefaultClient).AnyTimes()
+
+	ictx := mocks.NewMockInvocationContext(ctrl)
+	ictx.EXPECT().GetEnhancedLogger().Return(&logger).AnyTimes()
+	ictx.EXPECT().GetConfiguration().Return(config).AnyTimes()
+	ictx.EXPECT().GetUserInterface().Return(userInterface).AnyTimes()
+	ictx.EXPECT().GetNetworkAccess().Return(networkAccess).AnyTimes()
+	ictx.EXPECT().Context().Return(ctx).AnyTimes()
+	return ictx
+}
+
+func TestGetOrDownloadBinary_NoPublicKey(t *testing.T) {
+	const assetName = "mcp-scan-0.4.2-linux-x86_64"
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if filepath.Ext(r.URL.Path) == cache.SignatureSuffix {
+			t.Errorf("unexpected signature request without a public key: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		downloads++
+		_, _ = io.WriteString(w, testHelloWorld)
+	}))
+	defer server.Close()
+
+	local := writeTempFile(t, testHelloWorld)
+	if _, err := getOrDownloadBinary(newDownloadTestContext(t, t.TempDir()), Binary{
+		Version: "0.4.2", Checksum: sha256Hex(testHelloWorld), LocalPath: local,
+	}); err != nil {
+		t.Fatalf("expected local binary to be verified by checksum alone: %v", err)
+	}
+
+	cacheDir := t.TempDir()
+	binary := Binary{Version: "0.4.2", Asset: assetName, Checksum: sha256Hex(testHelloWorld), DownloadURL: server.URL}
+	for range 2 {
+		path, err := getOrDownloadBinary(newDownloadTestContext(t, cacheDir), binary)
+		if err != nil || path != filepath.Join(cacheDir, assetName) {
+			t.Fatalf("expected binary to be downloaded and cached, got %q: %v", path, err)
+		}
+	}
+	if downloads != 1 {
+		t.Fatalf("expected the cached binary to be reused without a signature, got %d downloads", downloads)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, assetName) + cache.SignatureSuffix); !os.IsNotExist(err) {
+		t.Fatalf("expected no signature to be cached without a public key: %v", err)
+	}
+}
+
+func TestGetOrDownloadBinary_Mirror(t *testing.T) {
+	const assetName = "mcp-scan-0.4.2-linux-x86_64"
+	key := newTestSigningKey(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mirror-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/releases/v0.4.2/" + assetName:
+			_, _ = io.WriteString(w, testHelloWorld)
+		case "/releases/v0.4.2/" + assetName + cache.SignatureSuffix:
+			_, _ = w.Write(key.sign([]byte(testHelloWorld), false))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	path, err := getOrDownloadBinary(newDownloadTestContext(t, cacheDir), Binary{
+		Version:         "0.4.2",
+		Asset:           assetName,
+		Checksum:        sha256Hex(testHelloWorld),
+		DownloadURL:     server.URL + "/releases",
+		DownloadHeaders: []string{"Authorization: Bearer mirror-token"},
+		PublicKey:       key.publicKey,
+	})
+	if err != nil {
+		t.Fatalf("download from mirror failed: %v", err)
+	}
+	if path != filepath.Join(cacheDir, assetName) {
+		t.Fatalf("expected binary to be cached, got %q", path)
+	}
+	if _, err := os.Stat(path + cache.SignatureSuffix); err != nil {
+		t.Fatalf("expected signature to be cached with the binary: %v", err)
+	}
+}
+
+func TestGetOrDownloadBinary_CachedSignature(t *testing.T) {
+	const assetName = "mcp-scan-0.4.2-linux-x86_64"
+	key := newTestSigningKey(t)
+
+	tests := map[string]struct {
+		signature    []byte
+		wantDownload bool
+	}{
+		"valid":     {signature: key.sign([]byte(testHelloWorld), false)},
+		"untrusted": {signature: newTestSigningKey(t).sign([]byte(testHelloWorld), false), wantDownload: true},
+		"missing":   {wantDownload: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			downloaded := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if filepath.Ext(r.URL.Path) == cache.SignatureSuffix {
+					_, _ = w.Write(key.sign([]byte(testHelloWorld), false))
+					return
+				}
+				downloaded = true
+				_, _ = io.WriteString(w, testHelloWorld)
+			}))
+			defer server.Close()
+
+			cacheDir := t.TempDir()
+			cachePath := filepath.Join(cacheDir, assetName)
+			if err := os.WriteFile(cachePath, []byte(testHelloWorld), 0o700); err != nil {
+				t.Fatalf("failed to populate cache: %v", err)
+			}
+			if tt.signature != nil {
+				if err := os.WriteFile(cachePath+cache.SignatureSuffix, tt.signature, 0o600); err != nil {
+					t.Fatalf("failed to populate cache: %v", err)
+				}
+			}
+
+			path, err := getOrDownloadBinary(newDownloadTestContext(t, cacheDir), Binary{
+				Version:     "0.4.2",
+				Asset:       assetName,
+				Checksum:    sha256Hex(testHelloWorld),
+				DownloadURL: server.URL,
+				PublicKey:   key.publicKey,
+			})
+			if err != nil || path != cachePath {
+				t.Fatalf("expected cached binary %q to be used, got %q: %v", cachePath, path, err)
+			}
+			if downloaded != tt.wantDownload {
+				t.Fatalf("expected download %v, got %v", tt.wantDownload, downloaded)
+			}
+			if err := verifyStoredSignature(cachePath, key.publicKey); err != nil {
+				t.Fatalf("expected a valid signature to be cached: %v", err)
+			}
+		})
+	}
+}
+
+func TestRenderDownloadURL(t *testing.T) {
+	tests := []struct {
+		template string
+		expected string
+		wantErr  bool
+	}{
+		{template: "", expected: "https://github.com/snyk/agent-scan/releases/download/v0.4.2/mcp-scan-0.4.2-linux-x86_64"},
+		{template: "https://artifactory.example.com/github/snyk/agent-scan/", expected: "https://artifactory.example.com/github/snyk/agent-scan/v0.4.2/mcp-scan-0.4.2-linux-x86_64"},
+		{template: "https://nexus.example.com/mcp-scan/{version}/{asset}", expected: "https://nexus.example.com/mcp-scan/0.4.2/mcp-scan-0.4.2-linux-x86_64"},
+		{template: "ftp://mirror.example.com/{asset}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := renderDownloadURL(tt.template, "0.4.2", "v0.4.2", "mcp-scan-0.4.2-linux-x86_64")
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("expected template %q to be rejected", tt.template)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for template %q: %v", tt.template, err)
+		}
+		if got != tt.expected {
+			t.Errorf("template %q rendered %q, expected %q", tt.template, got, tt.expected)
+		}
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := parseHeaders([]string{"Authorization: Bearer abc:def", "X-JFrog-Art-Api:key"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if headers.Get("Authorization") != "Bearer abc:def" || headers.Get("X-JFrog-Art-Api") != "key" {
+		t.Fatalf("unexpected headers: %v", headers)
+	}
+
+	if _, err := parseHeaders([]string{"no separator"}); err == nil {
+		t.Fatalf("expected header without separator to be rejected")
+	}
+}
+
+func TestGetOrDownloadBinary_SignatureRequired(t *testing.T) {
+	key := newTestSigningKey(t)
+	other := newTestSigningKey(t)
+
+	for name, signer := range map[string]*testSigningKey{"valid": key, "untrusted": other} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if filepath.Ext(r.URL.Path) == cache.SignatureSuffix {
+					_, _ = w.Write(signer.sign([]byte(testHelloWorld), false))
+					return
+				}
+				_, _ = io.WriteString(w, testHelloWorld)
+			}))
+			defer server.Close()
+
+			_, err := getOrDownloadBinary(newDownloadTestContext(t, t.TempDir()), Binary{
+				Version:     "0.4.2",
+				Asset:       "mcp-scan-0.4.2-linux-x86_64",
+				Checksum:    sha256Hex(testHelloWorld),
+				DownloadURL: server.URL,
+				PublicKey:   key.publicKey,
+			})
+			if signer == key && err != nil {
+				t.Fatalf("expected correctly signed binary to be accepted: %v", err)
+			}
+			if signer != key && err == nil {
+				t.Fatalf("expected binary signed by an untrusted key to be refused")
+			}
+		})
+	}
+}
+
+func TestAcquireBinary_HoldsSharedLock(t *testing.T) {
+	const assetName = "mcp-scan-0.4.2-linux-x86_64"
+	cacheDir := t.TempDir()
+	cachePath := filepath.Join(cacheDir, assetName)
+	if err := os.WriteFile(cachePath, []byte(testHelloWorld), 0o700); err != nil {
+		t.Fatalf("failed to populate cache: %v", err)
+	}
+
+	path, unlock, err := acquireBinary(newDownloadTestContext(t, cacheDir), Binary{
+		Version:   "0.4.2",
+		Asset:     assetName,
+		Checksum:  sha256Hex(testHelloWorld),
+		PublicKey: signFile(t, cachePath),
+	})
+	if err != nil || path != cachePath {
+		t.Fatalf("expected cached binary %q, got %q: %v", cachePath, path, err)
+	}
+
+	// Prune and clear take the exclusive lock before removing a binary.
+	if _, err := cache.Lock(context.Background(), cachePath, 300*time.Millisecond); !errors.Is(err, cache.ErrLockTimeout) {
+		t.Fatalf("expected the binary to stay locked until it is released, got %v", err)
+	}
+	unlock()
+	unlockExclusive, err := cache.Lock(context.Background(), cachePath, time.Second)
+	if err != nil {
+		t.Fatalf("expected the lock to be released: %v", err)
+	}
+	unlockExclusive()
+}
+
+func TestExecuteBinary_ConcurrentRunsShareCache(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on windows")
+	}
+	const assetName = "mcp-scan-0.4.2-linux-x86_64"
+	const script = "#!/bin/sh\nsleep 1\n"
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		_, _ = io.WriteString(w, script)
+	}))
+	defer server.Close()
+
+	// Each run holds the shared lock for longer than the lock timeout, so a run that waited for
+	// the other to finish would time out.
+	cacheDir := t.TempDir()
+	binary := Binary{
+		Version:     "0.4.2",
+		Asset:       assetName,
+		Checksum:    sha256Hex(script),
+		DownloadURL: server.URL,
+		LockTimeout: 500 * time.Millisecond,
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			exitCode, err := ExecuteBinary(newDownloadTestContext(t, cacheDir), nil, binary, nil, io.Discard)
+			if err == nil && exitCode != 0 {
+				err = fmt.Errorf("exit code %d", exitCode)
+			}
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("run %d failed: %v", i, err)
+		}
+	}
+	if n := downloads.Load(); n != 1 {
+		t.Fatalf("expected the binary to be downloaded once, got %d downloads", n)
+	}
+}
+
+func TestGetOrDownloadBinary_LockTimeout(t *testing.T) {
+	cacheDir := t.TempDir()
+	const assetName = "mcp-scan-0.4.2-linux-x86_64"
+	unlock, err := cache.Lock(context.Background(), filepath.Join(cacheDir, assetName), time.Second)
+	if err != nil {
+		t.Fatalf("failed to take cache lock: %v", err)
+	}
+	defer unlock()
+
+	_, err = getOrDownloadBinary(newDownloadTestContext(t, cacheDir), Binary{
+		Version:     "0.4.2",
+		Asset:       assetName,
+		Checksum:    sha256Hex(testHelloWorld),
+		PublicKey:   newTestSigningKey(t).publicKey,
+		LockTimeout: 300 * time.Millisecond,
+	})
+
+	var snykErr snyk_errors.Error
+	if !errors.As(err, &snykErr) {
+		t.Fatalf("expected an error catalog error while another process holds the lock, got %v", err)
+	}
+	if !errors.Is(err, cache.ErrLockTimeout) {
+		t.Fatalf("expected lock timeout to be the cause, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// sandboxScratchPattern names the writable directory created for every sandboxed run.
+const sandboxScratchPattern = "mcp-scan-sandbox-"
+
+// SandboxSupported reports whether the scanner can be sandboxed on this platform.
+func SandboxSupported() bool {
+	return runtime.GOOS == "linux"
+}
+
+// sandboxPolicy describes the restrictions applied to the scanner and every process it starts.
+type sandboxPolicy struct {
+	// scratchDir is the only directory, besides devices under /dev, the processes may write to.
+	scratchDir string
+	// warn reports a restriction that could not be applied.
+	warn func(message string)
+}
+
+// newSandboxPolicy creates the scratch directory of a sandboxed run. The returned cleanup
+// removes it together with everything the scanner and its MCP servers left there.
+func newSandboxPolicy(warn func(message string)) (*sandboxPolicy, func(), error) {
+	scratchDir, err := os.MkdirTemp("", sandboxScratchPattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	policy := &sandboxPolicy{scratchDir: scratchDir, warn: warn}
+	return policy, func() { _ = os.RemoveAll(scratchDir) }, nil
+}
+
+// environment points temporary files and the caches of common MCP server launchers into the
+// scratch directory, as the home directory is read-only inside the sandbox.
+func (s *sandboxPolicy) environment() []string {
+	cacheDir := filepath.Join(s.scratchDir, "cache")
+	return []string{
+		"TMPDIR=" + s.scratchDir,
+		"XDG_CACHE_HOME=" + cacheDir,
+		"npm_config_cache=" + filepath.Join(cacheDir, "npm"),
+		"UV_CACHE_DIR=" + filepath.Join(cacheDir, "uv"),
+	}
+}
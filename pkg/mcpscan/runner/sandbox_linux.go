@@ -0,0 +1,210 @@
+//go:build linux
+
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"unsafe"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/sys/unix"
+)
+
+// Resource limits of every sandboxed process. The process count is enforced by the kernel per
+// user, so it also includes processes of the user running outside the sandbox.
+const (
+	sandboxCPUSeconds  = 30 * 60
+	sandboxMemoryBytes = 4 << 30
+	sandboxProcesses   = 4096
+)
+
+// Filesystem rights introduced by each Landlock ABI version, see landlock(7).
+const (
+	landlockAccessFSv1 = unix.LANDLOCK_ACCESS_FS_EXECUTE |
+		unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_DIR |
+		unix.LANDLOCK_ACCESS_FS_REMOVE_DIR |
+		unix.LANDLOCK_ACCESS_FS_REMOVE_FILE |
+		unix.LANDLOCK_ACCESS_FS_MAKE_CHAR |
+		unix.LANDLOCK_ACCESS_FS_MAKE_DIR |
+		unix.LANDLOCK_ACCESS_FS_MAKE_REG |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_FIFO |
+		unix.LANDLOCK_ACCESS_FS_MAKE_BLOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SYM
+	landlockReadExecute = unix.LANDLOCK_ACCESS_FS_EXECUTE |
+		unix.LANDLOCK_ACCESS_FS_READ_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_DIR
+	landlockDevices = unix.LANDLOCK_ACCESS_FS_READ_FILE |
+		unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_DIR |
+		unix.LANDLOCK_ACCESS_FS_TRUNCATE |
+		unix.LANDLOCK_ACCESS_FS_IOCTL_DEV
+)
+
+// errLandlockUnsupported is returned when the kernel was built without Landlock or it is disabled.
+var errLandlockUnsupported = errors.New("Landlock is not supported or not enabled by this kernel")
+
+// startSandboxed starts cmd with no_new_privs, a Landlock ruleset that makes the filesystem
+// read-only except for the scratch directory, and resource limits.
+//
+// no_new_privs and Landlock restrict the calling thread and are inherited by the processes it
+// forks, so cmd is started from a dedicated, locked OS thread. The thread stays alive until the
+// scanner exited, as the parent death signal is tied to the thread that forked it, and is never
+// unlocked, so the Go runtime discards it instead of reusing its restrictions for other goroutines.
+func startSandboxed(cmd *exec.Cmd, sandbox *sandboxPolicy, logger *zerolog.Logger) (<-chan error, error) {
+	started := make(chan error, 1)
+	done := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		if err := restrictThread(sandbox, logger); err != nil {
+			started <- err
+			return
+		}
+		cmd.Path = resolveThroughThread(cmd.Path)
+		if err := cmd.Start(); err != nil {
+			started <- err
+			return
+		}
+		limitResources(cmd.Process.Pid, sandbox, logger)
+		started <- nil
+		done <- cmd.Wait()
+	}()
+
+	if err := <-started; err != nil {
+		return nil, err
+	}
+	return done, nil
+}
+
+// restrictThread applies no_new_privs and, where the kernel supports it, the Landlock ruleset to
+// the calling thread.
+func restrictThread(sandbox *sandboxPolicy, logger *zerolog.Logger) error {
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("failed to set no_new_privs for the sandbox: %w", err)
+	}
+
+	abi, err := landlockABI()
+	if errors.Is(err, errLandlockUnsupported) {
+		sandbox.warn("filesystem restrictions are not applied: " + err.Error())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := restrictFilesystem(abi, sandbox.scratchDir); err != nil {
+		return fmt.Errorf("failed to apply the filesystem restrictions of the sandbox: %w", err)
+	}
+	logger.Debug().Int("landlockABI", abi).Str("scratchDir", sandbox.scratchDir).Msg("Applied sandbox filesystem restrictions")
+	return nil
+}
+
+// landlockABI returns the Landlock ABI version implemented by the kernel.
+func landlockABI() (int, error) {
+	abi, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	switch errno {
+	case 0:
+		return int(abi), nil
+	case unix.ENOSYS, unix.EOPNOTSUPP:
+		return 0, errLandlockUnsupported
+	default:
+		return 0, fmt.Errorf("failed to query the Landlock version: %w", errno)
+	}
+}
+
+// landlockHandledAccess returns the filesystem rights the given ABI version can restrict.
+func landlockHandledAccess(abi int) uint64 {
+	access := uint64(landlockAccessFSv1)
+	if abi >= 2 {
+		access |= unix.LANDLOCK_ACCESS_FS_REFER
+	}
+	if abi >= 3 {
+		access |= unix.LANDLOCK_ACCESS_FS_TRUNCATE
+	}
+	if abi >= 5 {
+		access |= unix.LANDLOCK_ACCESS_FS_IOCTL_DEV
+	}
+	return access
+}
+
+// restrictFilesystem allows reading and executing everything, full access beneath scratchDir and
+// reading and writing devices such as /dev/null or the terminal; all other writes are denied.
+func restrictFilesystem(abi int, scratchDir string) error {
+	handled := landlockHandledAccess(abi)
+	attr := unix.LandlockRulesetAttr{Access_fs: handled}
+	fd, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return fmt.Errorf("failed to create ruleset: %w", errno)
+	}
+	rulesetFd := int(fd)
+	defer unix.Close(rulesetFd)
+
+	rules := []struct {
+		path   string
+		access uint64
+	}{
+		{path: "/", access: landlockReadExecute},
+		{path: "/dev", access: landlockDevices & handled},
+		{path: scratchDir, access: handled},
+	}
+	for _, rule := range rules {
+		if err := addLandlockRule(rulesetFd, rule.path, rule.access); err != nil {
+			return err
+		}
+	}
+
+	if _, _, errno := unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, uintptr(rulesetFd), 0, 0); errno != 0 {
+		return fmt.Errorf("failed to enforce ruleset: %w", errno)
+	}
+	return nil
+}
+
+func addLandlockRule(rulesetFd int, path string, access uint64) error {
+	pathFd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer unix.Close(pathFd)
+
+	rule := unix.LandlockPathBeneathAttr{Allowed_access: access, Parent_fd: int32(pathFd)} //nolint:gosec // file descriptors fit into int32
+	_, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, uintptr(rulesetFd), unix.LANDLOCK_RULE_PATH_BENEATH,
+		uintptr(unsafe.Pointer(&rule)), 0, 0, 0)
+	if errno != 0 {
+		return fmt.Errorf("failed to allow access to %s: %w", path, errno)
+	}
+	return nil
+}
+
+// limitResources lowers the CPU time, memory and process count limits of the started scanner.
+// Resource limits are shared by all threads of a process, so unlike the other restrictions they
+// cannot be set up before forking without limiting the CLI itself; they apply from the moment the
+// scanner started, and processes it forked before that keep their own limits.
+func limitResources(pid int, sandbox *sandboxPolicy, logger *zerolog.Logger) {
+	limits := []struct {
+		name     string
+		resource int
+		value    uint64
+	}{
+		{name: "CPU time", resource: unix.RLIMIT_CPU, value: sandboxCPUSeconds},
+		{name: "memory", resource: unix.RLIMIT_DATA, value: sandboxMemoryBytes},
+		{name: "process count", resource: unix.RLIMIT_NPROC, value: sandboxProcesses},
+	}
+	for _, limit := range limits {
+		var current unix.Rlimit
+		err := unix.Prlimit(pid, limit.resource, nil, &current)
+		if err == nil {
+			// Never raise a limit that is already lower than the sandbox default.
+			value := min(limit.value, current.Max)
+			err = unix.Prlimit(pid, limit.resource, &unix.Rlimit{Cur: value, Max: value}, nil)
+		}
+		if err != nil {
+			sandbox.warn(fmt.Sprintf("%s limit is not applied: %v", limit.name, err))
+			continue
+		}
+		logger.Debug().Str("limit", limit.name).Uint64("value", limit.value).Msg("Applied sandbox resource limit")
+	}
+}
@@ -0,0 +1,68 @@
+//go:build linux
+
+package runner //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExecuteBinary_Sandbox(t *testing.T) {
+	const script = `#!/bin/sh
+grep NoNewPrivs /proc/self/status
+echo probe > "$TMPDIR/probe" && echo scratch writable
+if (echo probe > "$1/probe") 2>/dev/null; then echo outside writable; else echo outside denied; fi
+`
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+	path := writeScript(t, t.TempDir(), script)
+	outside := t.TempDir()
+
+	var stdout bytes.Buffer
+	exitCode, err := ExecuteBinary(newMockInvocationContext(t), []string{outside}, Binary{
+		Version:   "0.4.2",
+		Checksum:  sha256Hex(script),
+		LocalPath: path,
+		PublicKey: signFile(t, path),
+		Sandbox:   true,
+	}, nil, &stdout)
+	if err != nil || exitCode != 0 {
+		t.Fatalf("expected sandboxed binary to run, got exit code %d: %v\n%s", exitCode, err, stdout.String())
+	}
+
+	output := stdout.String()
+	if !strings.Contains(output, "NoNewPrivs:\t1") {
+		t.Errorf("expected no_new_privs to be set, got %q", output)
+	}
+	if !strings.Contains(output, "scratch writable") {
+		t.Errorf("expected the scratch directory to be writable, got %q", output)
+	}
+	if _, err := landlockABI(); err != nil {
+		t.Logf("skipping filesystem checks: %v", err)
+	} else if !strings.Contains(output, "outside denied") {
+		t.Errorf("expected writes outside the scratch directory to be denied, got %q", output)
+	}
+
+	leftovers, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to read temp dir: %v", err)
+	}
+	if len(leftovers) != 0 {
+		t.Fatalf("expected the scratch directory to be removed, found %d entries", len(leftovers))
+	}
+}
+
+func TestLandlockHandledAccess(t *testing.T) {
+	v1 := landlockHandledAccess(1)
+	if v1&landlockReadExecute != landlockReadExecute {
+		t.Fatalf("expected ABI 1 to handle reading and executing files")
+	}
+	if landlockDevices&^landlockHandledAccess(5) != 0 {
+		t.Fatalf("expected ABI 5 to handle all device rights")
+	}
+	if landlockHandledAccess(2) == v1 || landlockHandledAccess(3) == landlockHandledAccess(2) {
+		t.Fatalf("expected newer ABI versions to handle more rights")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package runner
+
+import (
+	"errors"
+	"os/exec"
+
+	"github.com/rs/zerolog"
+)
+
+var errSandboxUnsupported = errors.New("the mcp-scan sandbox is only supported on Linux")
+
+func startSandboxed(_ *exec.Cmd, _ *sandboxPolicy, _ *zerolog.Logger) (<-chan error, error) {
+	return nil, errSandboxUnsupported
+}
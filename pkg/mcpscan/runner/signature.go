@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// maxSignatureSize bounds how much of a signature file is read; minisign signatures are well below 1 KiB.
+const maxSignatureSize = 4096
+
+const (
+	minisignAlgorithmLegacy    = "Ed"
+	minisignAlgorithmPrehashed = "ED"
+	minisignTrustedPrefix      = "trusted comment: "
+	minisignKeyIDLength        = 8
+)
+
+type minisignSignature struct {
+	algorithm       string
+	keyID           []byte
+	signature       []byte
+	trustedComment  string
+	globalSignature []byte
+}
+
+func decodeMinisignPublicKey(encoded string) (keyID []byte, key ed25519.PublicKey, err error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid signing public key: %w", err)
+	}
+	if len(raw) != 2+minisignKeyIDLength+ed25519.PublicKeySize || string(raw[:2]) != minisignAlgorithmLegacy {
+		return nil, nil, fmt.Errorf("invalid signing public key: unexpected format")
+	}
+	return raw[2 : 2+minisignKeyIDLength], ed25519.PublicKey(raw[2+minisignKeyIDLength:]), nil
+}
+
+func parseMinisignSignature(data []byte) (*minisignSignature, error) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], minisignTrustedPrefix) {
+		return nil, fmt.Errorf("invalid signature: unexpected format")
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(raw) != 2+minisignKeyIDLength+ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid signature: malformed signature line")
+	}
+	globalSignature, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(globalSignature) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid signature: malformed global signature")
+	}
+
+	return &minisignSignature{
+		algorithm:       string(raw[:2]),
+		keyID:           raw[2 : 2+minisignKeyIDLength],
+		signature:       raw[2+minisignKeyIDLength:],
+		trustedComment:  strings.TrimPrefix(lines[2], minisignTrustedPrefix),
+		globalSignature: globalSignature,
+	}, nil
+}
+
+// verifyFileSignature checks a minisign signature of the file at path against the given public key.
+func verifyFileSignature(path string, signatureData []byte, publicKey string) error {
+	keyID, key, err := decodeMinisignPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	sig, err := parseMinisignSignature(signatureData)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(sig.keyID, keyID) {
+		return fmt.Errorf("signature was created with an unknown key")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s for signature verification: %w", path, err)
+	}
+	defer f.Close()
+
+	var message []byte
+	switch sig.algorithm {
+	case minisignAlgorithmPrehashed:
+		h, _ := blake2b.New512(nil)
+		if _, err := io.Copy(h, f); err != nil {
+			return fmt.Errorf("failed to hash %s for signature verification: %w", path, err)
+		}
+		message = h.Sum(nil)
+	case minisignAlgorithmLegacy:
+		if message, err = io.ReadAll(f); err != nil {
+			return fmt.Errorf("failed to read %s for signature verification: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("unsupported signature algorithm %q", sig.algorithm)
+	}
+
+	if !ed25519.Verify(key, message, sig.signature) {
+		return fmt.Errorf("signature does not match %s", path)
+	}
+	if !ed25519.Verify(key, append(append([]byte{}, sig.signature...), sig.trustedComment...), sig.globalSignature) {
+		return fmt.Errorf("signature has an invalid trusted comment")
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package runner //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/cache"
+)
+
+type testSigningKey struct {
+	keyID      []byte
+	private    ed25519.PrivateKey
+	publicKey  string
+	trustedMsg string
+}
+
+func newTestSigningKey(t *testing.T) *testSigningKey {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	encoded := append(append([]byte("Ed"), keyID...), public...)
+	return &testSigningKey{
+		keyID:      keyID,
+		private:    private,
+		publicKey:  base64.StdEncoding.EncodeToString(encoded),
+		trustedMsg: "timestamp:1700000000\tfile:mcp-scan",
+	}
+}
+
+// sign produces a minisign signature file for contents, prehashed unless legacy is set.
+func (k *testSigningKey) sign(contents []byte, legacy bool) []byte {
+	algorithm := "ED"
+	message := contents
+	if legacy {
+		algorithm = "Ed"
+	} else {
+		digest := blake2b.Sum512(contents)
+		message = digest[:]
+	}
+	signature := ed25519.Sign(k.private, message)
+	global := ed25519.Sign(k.private, append(append([]byte{}, signature...), k.trustedMsg...))
+	line := append(append([]byte(algorithm), k.keyID...), signature...)
+	return []byte(fmt.Sprintf("untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(line), k.trustedMsg, base64.StdEncoding.EncodeToString(global)))
+}
+
+// signFile stores a signature of the file at path next to it and returns the public key to verify it with.
+func signFile(t *testing.T, path string) string {
+	t.Helper()
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	key := newTestSigningKey(t)
+	if err := os.WriteFile(path+cache.SignatureSuffix, key.sign(contents, false), 0o600); err != nil {
+		t.Fatalf("failed to write signature: %v", err)
+	}
+	return key.publicKey
+}
+
+func TestVerifyFileSignature(t *testing.T) {
+	key := newTestSigningKey(t)
+	path := writeTempFile(t, testHelloWorld)
+
+	for _, legacy := range []bool{false, true} {
+		if err := verifyFileSignature(path, key.sign([]byte(testHelloWorld), legacy), key.publicKey); err != nil {
+			t.Fatalf("expected valid signature (legacy=%v) to verify: %v", legacy, err)
+		}
+	}
+}
+
+func TestVerifyFileSignature_TamperedFile(t *testing.T) {
+	key := newTestSigningKey(t)
+	path := writeTempFile(t, testHelloWorld)
+	signature := key.sign([]byte(testHelloWorld), false)
+
+	if err := os.WriteFile(path, []byte("tampered"), 0o600); err != nil {
+		t.Fatalf("failed to tamper file: %v", err)
+	}
+	if err := verifyFileSignature(path, signature, key.publicKey); err == nil {
+		t.Fatalf("expected signature of tampered file to fail")
+	}
+}
+
+func TestVerifyFileSignature_OtherKey(t *testing.T) {
+	key := newTestSigningKey(t)
+	other := newTestSigningKey(t)
+	path := writeTempFile(t, testHelloWorld)
+
+	if err := verifyFileSignature(path, other.sign([]byte(testHelloWorld), false), key.publicKey); err == nil {
+		t.Fatalf("expected signature from another key to fail")
+	}
+}
+
+func TestVerifyFileSignature_Malformed(t *testing.T) {
+	key := newTestSigningKey(t)
+	path := writeTempFile(t, testHelloWorld)
+
+	if err := verifyFileSignature(path, []byte("not a signature"), key.publicKey); err == nil {
+		t.Fatalf("expected malformed signature to fail")
+	}
+	if err := verifyFileSignature(path, key.sign([]byte(testHelloWorld), false), "bm90IGEga2V5"); err == nil {
+		t.Fatalf("expected malformed public key to fail")
+	}
+}
@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/proxy"
+)
+
+// staleTempFileAge is how long a leftover temp file is kept before it is considered abandoned;
+// files of runs still in progress are younger than that.
+const staleTempFileAge = 24 * time.Hour
+
+// legacyTempBinaryPattern matches the executable copies older versions created with os.CreateTemp("", "mcp-scan-*").
+// Cached binaries, which may share the directory, always carry a version and platform in their name.
+var legacyTempBinaryPattern = regexp.MustCompile(`^mcp-scan-\d+$`)
+
+func isStaleTempFileName(name string) bool {
+	return legacyTempBinaryPattern.MatchString(name) ||
+		(strings.HasPrefix(name, proxy.CertFilePrefix) && strings.HasSuffix(name, proxy.CertFileSuffix))
+}
+
+// SweepStaleTempFiles removes scanner copies and proxy certificates that crashed or older runs of
+// this extension left in dirs; certificates of the Snyk CLI itself are left alone. It returns the number of removed files.
+func SweepStaleTempFiles(logger *zerolog.Logger, dirs ...string) int {
+	removed := 0
+	seen := map[string]bool{}
+	cutoff := time.Now().Add(-staleTempFileAge)
+	for _, dir := range dirs {
+		if dir == "" || seen[filepath.Clean(dir)] {
+			continue
+		}
+		seen[filepath.Clean(dir)] = true
+
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			if !f.Type().IsRegular() || !isStaleTempFileName(f.Name()) {
+				continue
+			}
+			info, err := f.Info()
+			if err != nil || info.ModTime().After(cutoff) {
+				continue
+			}
+			path := filepath.Join(dir, f.Name())
+			if err := os.Remove(path); err != nil {
+				logger.Debug().Err(err).Str("path", path).Msg("Failed to remove stale temp file")
+				continue
+			}
+			removed++
+		}
+	}
+	if removed > 0 {
+		logger.Debug().Int("count", removed).Msg("Removed stale mcp-scan temp files")
+	}
+	return removed
+}
@@ -0,0 +1,99 @@
+package mcpscan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snyk/go-application-framework/pkg/configuration"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/errors"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/manifest"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/runner"
+)
+
+// resolveScannerBinary looks up the requested scanner version for the platform in the scanner manifest.
+func resolveScannerBinary(config configuration.Configuration, scannerManifest *manifest.Manifest, platform manifest.Platform) (runner.Binary, error) {
+	version, err := resolveScannerVersion(config, scannerManifest)
+	if err != nil {
+		return runner.Binary{}, err
+	}
+	asset, err := scannerManifest.Resolve(version, platform)
+	if err != nil {
+		return runner.Binary{}, err
+	}
+
+	timeout, err := resolveTimeout(config)
+	if err != nil {
+		return runner.Binary{}, err
+	}
+	passEnv, err := resolvePassEnv(config)
+	if err != nil {
+		return runner.Binary{}, err
+	}
+	sandbox := config.GetBool(FlagSandbox)
+	if sandbox && !runner.SandboxSupported() {
+		return runner.Binary{}, errors.NewInvalidFlagValueError(fmt.Sprintf("--%s is only supported on Linux", FlagSandbox)).SnykError
+	}
+
+	return runner.Binary{
+		Version:         version,
+		Asset:           asset.Name,
+		Checksum:        asset.Checksum,
+		LocalPath:       config.GetString(FlagScannerBinary),
+		DownloadURL:     config.GetString(FlagScannerDownloadURL),
+		DownloadHeaders: config.GetStringSlice(FlagScannerDownloadHeader),
+		PublicKey:       MCPScanBinaryPublicKey,
+		Timeout:         timeout,
+		PassEnv:         passEnv,
+		Sandbox:         sandbox,
+	}, nil
+}
+
+// resolveScannerVersion returns the requested scanner version, which must be pinned in the manifest.
+func resolveScannerVersion(config configuration.Configuration, scannerManifest *manifest.Manifest) (string, error) {
+	version := strings.TrimPrefix(strings.TrimSpace(config.GetString(FlagScannerVersion)), "v")
+	if version == "" {
+		version = MCPScanBinaryVersion
+	}
+	if _, err := scannerManifest.Release(version); err != nil {
+		return "", errors.NewInvalidFlagValueError(fmt.Sprintf("Invalid --%s: %s", FlagScannerVersion, err)).SnykError
+	}
+	return version, nil
+}
+
+// resolvePassEnv reads the environment variable patterns to pass to the scanner. Every value may
+// hold several comma separated patterns, which is how they are set through SNYK_MCP_SCAN_PASS_ENV.
+func resolvePassEnv(config configuration.Configuration) ([]string, error) {
+	var patterns []string
+	for _, value := range config.GetStringSlice(FlagPassEnv) {
+		for _, pattern := range strings.Split(value, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				patterns = append(patterns, pattern)
+			}
+		}
+	}
+	if err := runner.ValidatePassEnv(patterns); err != nil {
+		return nil, errors.NewInvalidFlagValueError(fmt.Sprintf("Invalid --%s: %s", FlagPassEnv, err)).SnykError
+	}
+	return patterns, nil
+}
+
+// resolveTimeout reads --timeout as a duration or a number of seconds, falling back to the
+// CLI wide timeout. Zero means the scan is not limited.
+func resolveTimeout(config configuration.Configuration) (time.Duration, error) {
+	value := strings.TrimSpace(config.GetString(FlagTimeout))
+	if value == "" {
+		return time.Duration(config.GetInt(configuration.TIMEOUT)) * time.Second, nil
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return 0, errors.NewInvalidFlagValueError(fmt.Sprintf("Invalid --%s: %q is not a duration like 90s or 10m", FlagTimeout, value)).SnykError
+	}
+	return timeout, nil
+}
@@ -0,0 +1,114 @@
+package mcpscan //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/snyk/error-catalog-golang-public/snyk_errors"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/manifest"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/runner"
+)
+
+var linuxAmd64 = manifest.Platform{OS: "linux", Arch: "amd64"}
+
+func loadTestManifest(t *testing.T) *manifest.Manifest {
+	t.Helper()
+	scannerManifest, err := manifest.Load()
+	require.NoError(t, err)
+	return scannerManifest
+}
+
+func TestResolveScannerBinary(t *testing.T) {
+	config := configuration.NewWithOpts()
+
+	binary, err := resolveScannerBinary(config, loadTestManifest(t), linuxAmd64)
+	require.NoError(t, err)
+	assert.Equal(t, MCPScanBinaryVersion, binary.Version)
+	assert.Equal(t, "mcp-scan-"+MCPScanBinaryVersion+"-linux-x86_64", binary.Asset)
+	assert.Len(t, binary.Checksum, 64)
+
+	config.Set(FlagScannerVersion, "v"+MCPScanBinaryVersion)
+	binary, err = resolveScannerBinary(config, loadTestManifest(t), linuxAmd64)
+	require.NoError(t, err)
+	assert.Equal(t, MCPScanBinaryVersion, binary.Version)
+}
+
+func TestResolveScannerBinary_UnknownVersion(t *testing.T) {
+	config := configuration.NewWithOpts()
+	config.Set(FlagScannerVersion, "0.0.1")
+
+	_, err := resolveScannerBinary(config, loadTestManifest(t), linuxAmd64)
+	var snykErr snyk_errors.Error
+	require.ErrorAs(t, err, &snykErr)
+	assert.Contains(t, snykErr.Detail, "available versions: "+MCPScanBinaryVersion)
+}
+
+func TestResolveScannerBinary_UnsupportedPlatform(t *testing.T) {
+	_, err := resolveScannerBinary(configuration.NewWithOpts(), loadTestManifest(t), manifest.Platform{OS: "windows", Arch: "amd64"})
+	assert.ErrorIs(t, err, manifest.ErrUnsupportedPlatform)
+}
+
+func TestResolveScannerBinary_Sandbox(t *testing.T) {
+	config := configuration.NewWithOpts()
+	config.Set(FlagSandbox, true)
+
+	binary, err := resolveScannerBinary(config, loadTestManifest(t), linuxAmd64)
+	if !runner.SandboxSupported() {
+		var snykErr snyk_errors.Error
+		require.ErrorAs(t, err, &snykErr)
+		return
+	}
+	require.NoError(t, err)
+	assert.True(t, binary.Sandbox)
+}
+
+func TestResolveTimeout(t *testing.T) {
+	tests := []struct {
+		value   string
+		cliWide int
+		want    time.Duration
+		wantErr bool
+	}{
+		{want: 0},
+		{cliWide: 120, want: 2 * time.Minute},
+		{value: "90", cliWide: 120, want: 90 * time.Second},
+		{value: "10m", want: 10 * time.Minute},
+		{value: "1h30m", want: 90 * time.Minute},
+		{value: "soon", wantErr: true},
+		{value: "-5s", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			config := configuration.NewWithOpts()
+			config.Set(FlagTimeout, tt.value)
+			if tt.cliWide > 0 {
+				config.Set(configuration.TIMEOUT, tt.cliWide)
+			}
+
+			got, err := resolveTimeout(config)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolvePassEnv(t *testing.T) {
+	config := configuration.NewWithOpts()
+	config.Set(FlagPassEnv, []string{"AWS_PROFILE, NVM_*", "GOPATH"})
+
+	patterns, err := resolvePassEnv(config)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AWS_PROFILE", "NVM_*", "GOPATH"}, patterns)
+
+	config.Set(FlagPassEnv, "AWS_[")
+	_, err = resolvePassEnv(config)
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+package mcpscan
+
+import (
+	"fmt"
+
+	"github.com/snyk/go-application-framework/pkg/configuration"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/errors"
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/results"
+)
+
+// resolveSeverityOptions reads --severity-threshold and --fail-on. Issues below the threshold
+// are not reported; reported issues at or above fail-on make the command exit with code 1.
+// fail-on defaults to the threshold and can never be lower than it.
+func resolveSeverityOptions(config configuration.Configuration) (threshold, failOn results.Severity, err error) {
+	threshold = results.SeverityLow
+	if value := config.GetString(FlagSeverityThreshold); value != "" {
+		threshold, err = results.ParseSeverity(value)
+		if err != nil {
+			return "", "", errors.NewInvalidFlagValueError(fmt.Sprintf("Invalid --%s: %s", FlagSeverityThreshold, err)).SnykError
+		}
+	}
+
+	failOn = threshold
+	if value := config.GetString(FlagFailOn); value != "" {
+		failOn, err = results.ParseSeverity(value)
+		if err != nil {
+			return "", "", errors.NewInvalidFlagValueError(fmt.Sprintf("Invalid --%s: %s", FlagFailOn, err)).SnykError
+		}
+		if failOn.Rank() < threshold.Rank() {
+			failOn = threshold
+		}
+	}
+
+	return threshold, failOn, nil
+}
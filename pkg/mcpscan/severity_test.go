@@ -0,0 +1,79 @@
+package mcpscan //nolint:testpackage // tests need access to internal helpers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/local_workflows/content_type"
+	"github.com/snyk/go-application-framework/pkg/local_workflows/json_schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-mcp-scan/pkg/mcpscan/results"
+)
+
+func TestResolveSeverityOptions(t *testing.T) {
+	tests := []struct {
+		name              string
+		severityThreshold string
+		failOn            string
+		wantThreshold     results.Severity
+		wantFailOn        results.Severity
+		wantErr           bool
+	}{
+		{name: "defaults", wantThreshold: results.SeverityLow, wantFailOn: results.SeverityLow},
+		{name: "fail-on defaults to threshold", severityThreshold: "high", wantThreshold: results.SeverityHigh, wantFailOn: results.SeverityHigh},
+		{name: "fail-on above threshold", severityThreshold: "low", failOn: "critical", wantThreshold: results.SeverityLow, wantFailOn: results.SeverityCritical},
+		{name: "fail-on below threshold is raised", severityThreshold: "high", failOn: "low", wantThreshold: results.SeverityHigh, wantFailOn: results.SeverityHigh},
+		{name: "invalid threshold", severityThreshold: "urgent", wantErr: true},
+		{name: "invalid fail-on", failOn: "all", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := configuration.NewWithOpts()
+			config.Set(FlagSeverityThreshold, tt.severityThreshold)
+			config.Set(FlagFailOn, tt.failOn)
+
+			threshold, failOn, err := resolveSeverityOptions(config)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantThreshold, threshold)
+			assert.Equal(t, tt.wantFailOn, failOn)
+		})
+	}
+}
+
+func TestNewScanOutput_TestSummary(t *testing.T) {
+	result := &results.ScanResult{
+		Paths: []results.PathResult{{Path: "mcp.json", Servers: []results.ServerResult{{Name: "fs"}}}},
+		Issues: []results.Issue{
+			{Code: "W001", Severity: results.SeverityMedium},
+			{Code: "E001", Severity: results.SeverityHigh},
+		},
+	}
+	result.UpdateSummary()
+
+	data, err := newScanOutput(result, MCPScanBinaryVersion, results.SeverityHigh, contentTypeJSON)
+	require.NoError(t, err)
+
+	var summary json_schemas.TestSummary
+	for _, d := range data {
+		if d.GetContentType() == content_type.TEST_SUMMARY {
+			payload, ok := d.GetPayload().([]byte)
+			require.True(t, ok)
+			require.NoError(t, json.Unmarshal(payload, &summary))
+		}
+	}
+
+	assert.Equal(t, 1, summary.Artifacts)
+	require.Len(t, summary.Results, 2, "only severities at or above fail-on are included")
+	assert.Equal(t, "high", summary.Results[0].Severity)
+	assert.Equal(t, 1, summary.Results[0].Open)
+	assert.Equal(t, "critical", summary.Results[1].Severity)
+	assert.Equal(t, 0, summary.Results[1].Open)
+}
@@ -9,10 +9,14 @@ import (
 const (
 	ScanWorkflowIDStr = "mcp-scan"
 
+	// MCPScanBinaryVersion is the scanner version used unless another version from the manifest is requested.
 	MCPScanBinaryVersion = "0.4.2"
 
-	MCPScanBinaryChecksumLinuxAmd64 = "06d372791ae93b5384da5c81b87e9c816ac7756c1d56810dd05329bfc10b5613"
-	MCPScanBinaryChecksumMacOSArm64 = "acb0ddc751d8dd8aba7243e366758e1d6d0b12b674f5ea900357dc79ac6de0fe"
+	// MCPScanBinaryPublicKey is the minisign public key the scanner release assets are signed with.
+	// The scanner releases do not publish signatures yet, so it is empty and binaries are verified
+	// against the checksum pinned in the manifest only. Once it is set, downloaded, cached and
+	// pre-provisioned binaries additionally need a valid signature.
+	MCPScanBinaryPublicKey = ""
 )
 
 var (
@@ -25,6 +29,12 @@ var (
 func Init(engine workflow.Engine) error {
 	flags := getFlagSet()
 	engine.GetConfiguration().AddAlternativeKeys(FlagTenantID, []string{"SNYK_TENANT_ID"})
+	engine.GetConfiguration().AddAlternativeKeys(FlagScannerBinary, []string{"SNYK_MCP_SCAN_BINARY"})
+	engine.GetConfiguration().AddAlternativeKeys(FlagScannerVersion, []string{"SNYK_MCP_SCAN_VERSION"})
+	engine.GetConfiguration().AddAlternativeKeys(FlagPassEnv, []string{"SNYK_MCP_SCAN_PASS_ENV"})
+	engine.GetConfiguration().AddAlternativeKeys(FlagAllowHost, []string{"SNYK_MCP_SCAN_ALLOW_HOST"})
+	engine.GetConfiguration().AddAlternativeKeys(FlagScannerDownloadURL, []string{"SNYK_MCP_SCAN_DOWNLOAD_URL"})
+	engine.GetConfiguration().AddAlternativeKeys(FlagScannerDownloadHeader, []string{"SNYK_MCP_SCAN_DOWNLOAD_HEADER"})
 	_, err := engine.Register(
 		ScanWorkflowID,
 		workflow.ConfigurationOptionsFromFlagset(flags),
@@ -33,5 +43,21 @@ func Init(engine workflow.Engine) error {
 		return fmt.Errorf("failed to register workflow: %w", err)
 	}
 
+	_, err = engine.Register(
+		CacheWorkflowID,
+		workflow.ConfigurationOptionsFromFlagset(getCacheFlagSet()),
+		CacheWorkflow)
+	if err != nil {
+		return fmt.Errorf("failed to register cache workflow: %w", err)
+	}
+
+	_, err = engine.Register(
+		DoctorWorkflowID,
+		workflow.ConfigurationOptionsFromFlagset(getDoctorFlagSet()),
+		DoctorWorkflow)
+	if err != nil {
+		return fmt.Errorf("failed to register doctor workflow: %w", err)
+	}
+
 	return nil
 }